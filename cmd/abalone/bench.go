@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"abalone_go/internal/board"
+	"abalone_go/internal/search"
+)
+
+// runBench `abalone bench`：同一组局面按不同线程数搜到固定深度，比较用时（time-to-depth），
+// 衡量 Lazy SMP 的加速比。
+//
+//	abalone bench -depth 5                 线程数 1、2、4……直到 CPU 核数
+//	abalone bench -depth 6 -threads 1,8,16
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	var (
+		depth   = fs.Int("depth", 5, "search depth for every position")
+		threads = fs.String("threads", "", "comma separated thread counts (default 1, 2, 4, ... up to the number of CPUs)")
+		hashPow = fs.Int("hash", 20, "transposition table size as a power of two entries; every search starts from an empty table")
+		plies   = fs.Int("plies", 10, "besides each start position, also search it after this many random (seeded) plies")
+	)
+	fs.Parse(args)
+
+	var counts []int
+	if *threads == "" {
+		for n := 1; n < runtime.NumCPU(); n *= 2 {
+			counts = append(counts, n)
+		}
+		counts = append(counts, runtime.NumCPU())
+	} else {
+		for _, s := range strings.Split(*threads, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || n < 1 {
+				fmt.Fprintf(os.Stderr, "bad thread count %q\n", s)
+				return 2
+			}
+			counts = append(counts, n)
+		}
+	}
+	if *depth < 1 || *depth > search.MaxDepth || *hashPow < 1 || *hashPow > 30 {
+		fmt.Fprintln(os.Stderr, "-depth must be between 1 and 64 and -hash between 1 and 30")
+		return 2
+	}
+
+	positions, err := benchPositions(*plies)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	fmt.Printf("%d positions, depth %d, %d CPUs\n\n", len(positions), *depth, runtime.NumCPU())
+	fmt.Printf("%-8s %-10s %-12s %-10s %s\n", "threads", "time", "nodes", "nps", "speedup")
+
+	var base time.Duration
+	for _, n := range counts {
+		var elapsed time.Duration
+		var nodes uint64
+		for _, g := range positions {
+			// 每个局面都用新引擎，置换表从空开始，避免前一次搜索的结果帮后一次的忙
+			e := search.NewEngine(search.Options{Threads: n, HashPow: uint8(*hashPow)})
+			e.Search(context.Background(), g, search.Limits{Depth: int8(*depth)})
+			elapsed += e.Stats().Elapsed
+			nodes += e.Stats().Nodes
+		}
+		if base == 0 {
+			base = elapsed
+		}
+		nps := float64(nodes) / max(elapsed.Seconds(), 1e-9)
+		fmt.Printf("%-8d %-10s %-12d %-10.0f %.2fx\n", n, elapsed.Round(time.Millisecond), nodes, nps,
+			base.Seconds()/max(elapsed.Seconds(), 1e-9))
+	}
+	return 0
+}
+
+// benchPositions 内置摆法与 variants.json 中的两种常用开局，各取开局和随机走 plies 步之后的局面
+func benchPositions(plies int) ([]*board.Game, error) {
+	rules := board.StandardRules()
+	var out []*board.Game
+	rng := rand.New(rand.NewSource(1))
+	for _, variant := range []string{"", "classical", "belgian-daisy"} {
+		g := board.NewGame(board.PlayerA, rules)
+		if variant != "" {
+			var err error
+			if g, err = board.NewGameFromVariant(variant, board.PlayerA, rules); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, g.Clone())
+		if plies <= 0 {
+			continue
+		}
+		for i := 0; i < plies && !g.Result.Over(); i++ {
+			moves := board.LegalMoves(g)
+			g.Play(moves[rng.Intn(len(moves))])
+		}
+		if !g.Result.Over() {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
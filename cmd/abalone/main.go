@@ -4,36 +4,214 @@ import (
 	"flag"
 	"fmt"
 	"math/rand"
+	"os"
 	"time"
 
 	"abalone_go/internal/board"
+	"abalone_go/internal/match"
+	"abalone_go/internal/record"
 	"abalone_go/internal/ui"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "perft":
+			os.Exit(runPerft(os.Args[2:]))
+		case "fuzz":
+			os.Exit(runFuzz(os.Args[2:]))
+		case "match":
+			os.Exit(runMatch(os.Args[2:]))
+		case "bench":
+			os.Exit(runBench(os.Args[2:]))
+		}
+	}
+
 	// ──────── 命令行参数 ────────
 	var (
 		randomStart = flag.Bool("random", false, "randomize starting player")
-		maxDepth    = flag.Int("depth", 4, "search depth for AI")
+		maxDepth    = flag.Int("depth", 4, "maximum search depth for AI (0 = as deep as -movetime allows)")
+		moveTime    = flag.Duration("movetime", 15*time.Second, "AI time limit per move")
 		mode        = flag.String("mode", "pve", "game mode: pve or pvp")
+		position    = flag.String("position", "", "start from a text position, e.g. \"v1 aaaaa/aaaaaa/aaa4/8/9/8/4bbb/bbbbbb/bbbbb a 0-0 1\"")
+		loadPath    = flag.String("load", "", "continue a saved game record")
+		savePath    = flag.String("save", "", "write the game record to this file on exit / game over")
+		variant     = flag.String("variant", "", "starting layout from variants.json, e.g. belgian-daisy (\"list\" to show all)")
+		rulesText   = flag.String("rules", "", "non-standard rules, e.g. \"win=3 broadside=off push=2:1\" or \"players=3\" (see board.ParseRules)")
+		maxMoves    = flag.Int("maxmoves", 0, "total move cap, e.g. 200 (0 = no limit)")
+		limitDraw   = flag.Bool("limitdraw", false, "a game reaching -maxmoves is a draw instead of decided by marble count")
+		bestOf      = flag.Int("bestof", 0, "play a match of N games, swapping colours every game (press N for the next game)")
+		firstTo     = flag.Int("firstto", 0, "play a match until one side has won K games")
+		setupText   = flag.String("setup", "", "custom starting layout as per-player cell lists, e.g. \"[[0,1,2,3],[57,58,59,60]]\" (same shape as players_sets in variants.json)")
+		handicap    = flag.String("handicap", "", "handicap, e.g. \"remove=A1,A5\" (take those marbles off) and/or \"headstart=0-2\" (captures already credited)")
 	)
 	flag.Parse()
 
+	if *variant == "list" {
+		for _, name := range board.Variants() {
+			fmt.Println(name)
+		}
+		return
+	}
+
 	// ──────── 初始化棋局 ────────
+	rules, err := board.ParseRules(*rulesText)
+	if err == nil && *maxMoves != 0 {
+		rules.MoveLimit = board.MoveLimit{Moves: *maxMoves, ByMarbles: !*limitDraw}
+		err = rules.Validate()
+	}
+	if err == nil && (*maxDepth < 0 || *moveTime <= 0) {
+		err = fmt.Errorf("-depth must be >= 0 and -movetime > 0")
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 	startPlayer := board.PlayerA
 	if *randomStart {
 		rand.Seed(time.Now().UnixNano())
-		startPlayer = board.PlayerA + int8(rand.Intn(2))
+		startPlayer = board.PlayerA + int8(rand.Intn(int(rules.Players)))
+	}
+	var setup board.Setup
+	if *setupText != "" {
+		if *variant != "" {
+			fmt.Fprintln(os.Stderr, "-setup cannot be combined with -variant")
+			os.Exit(2)
+		}
+		if setup, err = board.ParseSetup(*setupText); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	g := board.NewGame(startPlayer, rules)
+	switch {
+	case setup != nil:
+		g, err = board.NewGameFromSetup(setup, startPlayer, rules)
+	case *variant != "":
+		g, err = board.NewGameFromVariant(*variant, startPlayer, rules)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *handicap != "" {
+		h, err := g.ParseHandicap(*handicap)
+		if err == nil {
+			err = g.ApplyHandicap(h)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	if *position != "" {
+		if err := g.UnmarshalText([]byte(*position)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		startPlayer = g.CurrentPlayer
+	}
+
+	if *bestOf > 0 || *firstTo > 0 {
+		if *loadPath != "" || *handicap != "" {
+			// 每局换边，让子会轮流落到双方头上
+			fmt.Fprintln(os.Stderr, "-load and -handicap cannot be combined with -bestof / -firstto")
+			os.Exit(2)
+		}
+		playMatch(rules, *variant, setup, *position, *bestOf, *firstTo, *mode, *maxDepth, *moveTime, *savePath)
+		return
+	}
+
+	var rec *record.Game
+	if *loadPath != "" {
+		if rec, g, err = loadRecord(*loadPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		startPlayer = g.CurrentPlayer
+	} else {
+		rec = record.New(g)
+		if *position != "" {
+			rec.SetTag(record.TagPosition, *position)
+		}
+		if *mode == "pve" {
+			rec.SetTag(record.TagPlayerA, "human")
+			rec.SetTag(record.TagPlayerB, "abalone_go")
+			rec.SetTag(record.TagEngine, fmt.Sprintf("depth=%d time=%s", *maxDepth, *moveTime))
+		}
 	}
-	g := board.NewGame(startPlayer)
 
-	fmt.Printf("Abalone started: first move -> Player %d  |  mode=%s  |  depth=%d\n",
-		startPlayer, *mode, *maxDepth)
+	fmt.Printf("Abalone started: first move -> Player %d  |  mode=%s  |  depth=%d  |  variant=%s\n",
+		startPlayer, *mode, *maxDepth, g.Variant())
 
 	// ──────── 启动 UI 主循环 ────────
 	pve := (*mode == "pve") // true = 双人
 	gameLoop := ui.NewGameLoop(g, pve, int8(*maxDepth))
+	gameLoop.SetMoveTime(*moveTime)
+	if *savePath != "" {
+		gameLoop.RecordTo(*savePath, rec)
+	}
+	ui.Run(gameLoop)
+}
+
+// playMatch 在界面里进行多局比赛：pve 时人类为第一位参赛方，其余各方由 AI 走
+func playMatch(rules board.RuleSet, variant string, setup board.Setup, position string, bestOf, firstTo int, mode string, depth int, moveTime time.Duration, savePath string) {
+	pve := mode == "pve"
+	cfg := match.Config{
+		Names:    make([]string, rules.Players),
+		Rules:    rules,
+		Variant:  variant,
+		Setup:    setup,
+		Position: position,
+		BestOf:   bestOf,
+		FirstTo:  firstTo,
+	}
+	if pve {
+		cfg.Tags = []record.Tag{{Name: record.TagEngine, Value: fmt.Sprintf("depth=%d time=%s", depth, moveTime)}}
+	}
+	for i := range cfg.Names {
+		switch {
+		case !pve:
+			cfg.Names[i] = fmt.Sprintf("player %d", i+1)
+		case i == 0:
+			cfg.Names[i] = "human"
+		default:
+			cfg.Names[i] = "abalone_go"
+		}
+	}
+	m, err := match.New(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	fmt.Printf("Abalone match: %s  |  mode=%s  |  depth=%d  |  variant=%s\n", m.Format(), mode, depth, m.Game().Variant())
+
+	gameLoop := ui.NewGameLoop(m.Game(), pve, int8(depth))
+	gameLoop.SetMoveTime(moveTime)
+	gameLoop.PlayMatch(m)
+	if savePath != "" {
+		gameLoop.RecordTo(savePath, m.Record())
+	}
 	ui.Run(gameLoop)
 }
 
-// go build -ldflags="-s -w" -gcflags="all=-trimpath=${PWD}" -asmflags="all=-trimpath=${PWD}" -o abalone.exe .\cmd\abalone\main.go
+// loadRecord 读取文件中的第一局并重放到最后
+func loadRecord(path string) (*record.Game, *board.Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	rec, err := record.NewReader(f).Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	g, err := rec.Replay()
+	if err != nil {
+		return nil, nil, err
+	}
+	return rec, g, nil
+}
+
+// go build -ldflags="-s -w" -gcflags="all=-trimpath=${PWD}" -asmflags="all=-trimpath=${PWD}" -o abalone.exe .\cmd\abalone
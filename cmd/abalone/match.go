@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"abalone_go/internal/board"
+	"abalone_go/internal/match"
+	"abalone_go/internal/record"
+	"abalone_go/internal/search"
+)
+
+// runMatch `abalone match`：无界面的引擎对战，按比赛赛制连续下多局，每局换边。
+//
+//	abalone match -bestof 4 -depth 3,4 -save match.txt
+//	abalone match -firstto 3 -variant belgian-daisy -time 2s
+func runMatch(args []string) int {
+	fs := flag.NewFlagSet("match", flag.ExitOnError)
+	var (
+		bestOf    = fs.Int("bestof", 0, "play N games; ends early once the leader cannot be caught (default 2 when -firstto is not set)")
+		firstTo   = fs.Int("firstto", 0, "the first engine to win K games takes the match")
+		depths    = fs.String("depth", "3", "maximum search depth per engine, comma separated, e.g. 3,4 (0 = as deep as -time allows)")
+		moveTime  = fs.Duration("time", 15*time.Second, "time limit per move; the engine deepens iteratively and plays the last completed iteration")
+		variant   = fs.String("variant", "", "starting layout from variants.json")
+		position  = fs.String("position", "", "start every game from this text position")
+		rulesText = fs.String("rules", "", "non-standard rules (see board.ParseRules)")
+		maxMoves  = fs.Int("maxmoves", board.TournamentLimit.Moves, "total move cap per game, decided by marble count (0 = no limit)")
+		savePath  = fs.String("save", "", "write all game records to this file")
+		threads   = fs.Int("threads", 0, "search threads per engine (0 = number of CPUs - 1)")
+		showInfo  = fs.Bool("info", false, "print search info (depth, score, nodes, nps, principal variation) after every iteration")
+		hashPow   = fs.Int("hash", 0, "transposition table size per engine as a power of two entries, e.g. 20 (0 = default 22, 64 MiB)")
+	)
+	fs.Parse(args)
+
+	rules, err := board.ParseRules(*rulesText)
+	if err == nil && *maxMoves != 0 && rules.MoveLimit.Moves == 0 {
+		rules.MoveLimit = board.MoveLimit{Moves: *maxMoves, ByMarbles: true}
+		err = rules.Validate()
+	}
+	if err == nil && *moveTime <= 0 {
+		err = fmt.Errorf("-time must be positive")
+	}
+	if err == nil && (*threads < 0 || *hashPow < 0 || *hashPow > 30) {
+		err = fmt.Errorf("-threads must be >= 0 and -hash between 0 and 30")
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	// 每位参赛方一个深度；给出的个数不够时循环使用
+	var levels []int8
+	for _, s := range strings.Split(*depths, ",") {
+		d, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || d < 0 {
+			fmt.Fprintf(os.Stderr, "bad depth %q\n", s)
+			return 2
+		}
+		levels = append(levels, int8(d))
+	}
+	// 每位参赛方一个引擎，各有各的置换表
+	names := make([]string, rules.Players)
+	engines := map[string]*search.Engine{}
+	for i := range names {
+		d := levels[i%len(levels)]
+		names[i] = fmt.Sprintf("engine%d (depth=%d)", i+1, d)
+		opts := search.Options{
+			Threads: *threads,
+			HashPow: uint8(*hashPow),
+			Limits:  search.Limits{Depth: d, Hard: *moveTime},
+		}
+		if *showInfo {
+			opts.Info = func(info search.Info) { fmt.Println("    info", info) }
+		}
+		engines[names[i]] = search.NewEngine(opts)
+	}
+
+	if *bestOf == 0 && *firstTo == 0 {
+		*bestOf = 2
+	}
+	m, err := match.New(match.Config{
+		Names:    names,
+		Rules:    rules,
+		Variant:  *variant,
+		Position: *position,
+		BestOf:   *bestOf,
+		FirstTo:  *firstTo,
+		Event:    "abalone_go engine match",
+		Tags:     []record.Tag{{Name: record.TagTimeControl, Value: moveTime.String()}},
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	// Ctrl-C：叫停正在进行的搜索，照常输出比分并保存已下的棋谱
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	code := 0
+play:
+	for {
+		g := m.Game()
+		for c := int8(0); c < rules.Players; c++ {
+			fmt.Printf("%c: %s  ", 'A'+c, m.Name(c))
+		}
+		fmt.Printf("(game %d)\n", m.Round())
+		for !g.Result.Over() {
+			best, score, ok := engines[m.Name(g.CurrentPlayer)].Think(ctx, g)
+			if ctx.Err() != nil {
+				fmt.Printf("  interrupted after %d moves\n", g.TurnCount-1)
+				code = 1
+				break play
+			}
+			if !ok || len(best.Mods) == 0 {
+				fmt.Fprintf(os.Stderr, "no move found in %s\n", g)
+				return 1
+			}
+			mv := m.Play(best)
+			mv.HasEval, mv.Eval = true, score
+		}
+		fmt.Printf("  %s after %d moves\n", g.Result, g.TurnCount-1)
+		if !m.Next() {
+			break
+		}
+	}
+	fmt.Println(m)
+
+	if *savePath != "" {
+		if err := saveRecords(*savePath, m.Records()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return code
+}
+
+// saveRecords 把多局棋谱依次写入同一个文件
+func saveRecords(path string, records []*record.Game) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	wr := record.NewWriter(f)
+	for _, rec := range records {
+		if err := wr.Write(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
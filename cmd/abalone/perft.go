@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"abalone_go/internal/board"
+)
+
+// runPerft `abalone perft`：数走法树的叶子，校验走法生成。
+//
+//	abalone perft -depth 4                       内置摆法
+//	abalone perft -variant belgian-daisy -divide 按根节点走法拆开
+//	abalone perft -check -depth 4                与 board.PerftReference 逐项核对
+func runPerft(args []string) int {
+	fs := flag.NewFlagSet("perft", flag.ExitOnError)
+	var (
+		depth    = fs.Int("depth", 3, "number of plies to expand")
+		divide   = fs.Bool("divide", false, "print the node count below each root move")
+		position = fs.String("position", "", "start from a text position")
+		variant  = fs.String("variant", "", "starting layout from variants.json")
+		rulesTxt = fs.String("rules", "", "non-standard rules (see board.ParseRules)")
+		check    = fs.Bool("check", false, "compare against the reference table up to -depth and exit non-zero on mismatch")
+	)
+	fs.Parse(args)
+
+	if *check {
+		return perftCheck(*depth)
+	}
+
+	rules, err := board.ParseRules(*rulesTxt)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	g := board.NewGame(board.PlayerA, rules)
+	if *variant != "" {
+		if g, err = board.NewGameFromVariant(*variant, board.PlayerA, rules); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+	if *position != "" {
+		if err := g.UnmarshalText([]byte(*position)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	start := time.Now()
+	var nodes uint64
+	if *divide {
+		for _, e := range board.Divide(g, *depth) {
+			fmt.Printf("%-12s %d\n", e.Move, e.Nodes)
+			nodes += e.Nodes
+		}
+		fmt.Println()
+	} else {
+		nodes = board.Perft(g, *depth)
+	}
+	printPerft(*depth, nodes, time.Since(start))
+	return 0
+}
+
+// perftCheck 逐项重算参考表中不超过 maxDepth 的值
+func perftCheck(maxDepth int) int {
+	failed := 0
+	for _, c := range board.PerftReference {
+		name := c.Variant
+		g := board.NewGame(board.PlayerA, board.StandardRules())
+		if name == "" {
+			name = "default"
+		} else {
+			var err error
+			if g, err = board.NewGameFromVariant(c.Variant, board.PlayerA, board.StandardRules()); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 2
+			}
+		}
+		for d, want := range c.Counts {
+			if d+1 > maxDepth {
+				break
+			}
+			start := time.Now()
+			got := board.Perft(g, d+1)
+			status := "ok"
+			if got != want {
+				status = fmt.Sprintf("MISMATCH (want %d)", want)
+				failed++
+			}
+			fmt.Printf("%-14s depth %d  %12d  %-8s %s\n", name, d+1, got, time.Since(start).Round(time.Millisecond), status)
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("%d mismatches\n", failed)
+		return 1
+	}
+	return 0
+}
+
+func printPerft(depth int, nodes uint64, elapsed time.Duration) {
+	nps := float64(nodes) / max(elapsed.Seconds(), 1e-9)
+	fmt.Printf("depth %d  nodes %d  time %s  nps %.0f\n", depth, nodes, elapsed.Round(time.Millisecond), nps)
+}
@@ -0,0 +1,194 @@
+// File internal/board/bitboard.go
+package board
+
+import "math/bits"
+
+// bitboard 与 Cells 同形的位图：第 r*grid+c 位对应格子 (r,c)，最大棋盘 15×15 共 225 位，
+// 外圈 VOID 充当哨兵，整体平移后不会把一行的尾巴接到下一行的头上。
+type bitboard [bbWords]uint64
+
+const bbWords = (maxGrid*maxGrid + 63) / 64
+
+func (g *Game) gridBit(r, c int8) int { return int(r)*int(g.grid) + int(c) }
+
+func (b *bitboard) set(i int)   { b[i>>6] |= 1 << uint(i&63) }
+func (b *bitboard) clear(i int) { b[i>>6] &^= 1 << uint(i&63) }
+
+// 固定 4 个字，手工展开，比循环快
+func (b bitboard) and(o bitboard) bitboard {
+	return bitboard{b[0] & o[0], b[1] & o[1], b[2] & o[2], b[3] & o[3]}
+}
+
+func (b bitboard) or(o bitboard) bitboard {
+	return bitboard{b[0] | o[0], b[1] | o[1], b[2] | o[2], b[3] | o[3]}
+}
+
+func (b bitboard) andNot(o bitboard) bitboard {
+	return bitboard{b[0] &^ o[0], b[1] &^ o[1], b[2] &^ o[2], b[3] &^ o[3]}
+}
+
+func (b bitboard) not() bitboard { return bitboard{^b[0], ^b[1], ^b[2], ^b[3]} }
+
+func (b bitboard) empty() bool { return b[0]|b[1]|b[2]|b[3] == 0 }
+
+func (b bitboard) count() int {
+	return bits.OnesCount64(b[0]) + bits.OnesCount64(b[1]) + bits.OnesCount64(b[2]) + bits.OnesCount64(b[3])
+}
+
+// ahead 第 i 位取原位图第 i+k 位，即“沿步长 k 往前看一格”；移出范围的位丢弃
+func (b bitboard) ahead(k int) bitboard {
+	// 常见情形 |k| < 64：每个字只与相邻字拼接
+	switch s := uint(k); {
+	case k >= 0 && k < 64:
+		return bitboard{b[0]>>s | b[1]<<(64-s), b[1]>>s | b[2]<<(64-s), b[2]>>s | b[3]<<(64-s), b[3] >> s}
+	case k < 0 && k > -64:
+		s = uint(-k)
+		return bitboard{b[0] << s, b[1]<<s | b[0]>>(64-s), b[2]<<s | b[1]>>(64-s), b[3]<<s | b[2]>>(64-s)}
+	}
+	var out bitboard
+	if k >= 0 {
+		q, s := k>>6, uint(k&63)
+		for i := 0; i+q < bbWords; i++ {
+			out[i] = b[i+q] >> s
+			if i+q+1 < bbWords {
+				out[i] |= b[i+q+1] << (64 - s) // s==0 时移 64 位为 0
+			}
+		}
+		return out
+	}
+	q, s := (-k)>>6, uint((-k)&63)
+	for i := bbWords - 1; i-q >= 0; i-- {
+		out[i] = b[i-q] << s
+		if i-q-1 >= 0 {
+			out[i] |= b[i-q-1] >> (64 - s)
+		}
+	}
+	return out
+}
+
+// each 按位序回调每个置位的下标
+func (b bitboard) each(fn func(i int)) {
+	for k, w := range b {
+		for ; w != 0; w &= w - 1 {
+			fn(k*64 + bits.TrailingZeros64(w))
+		}
+	}
+}
+
+// ---- 与 Cells 同步 ----
+
+// syncBitboards 按 Cells 重建各方位图；整盘改写 Cells 后调用
+func (g *Game) syncBitboards() {
+	g.pieces = [MaxPlayers]bitboard{}
+	for p := int8(0); p < g.cells; p++ {
+		r, c := g.PosToCoord(p)
+		if t := g.Cells[r][c]; t >= 0 {
+			g.pieces[t].set(g.gridBit(r, c))
+		}
+	}
+}
+
+// movePiece 在位图上把 player 的子从 from 挪到 to（pos 索引）
+func (g *Game) movePiece(player, from, to int8) {
+	g.pieces[player].clear(g.posBit(from))
+	g.pieces[player].set(g.posBit(to))
+}
+
+func (g *Game) posBit(pos int8) int {
+	rc := g.posIndex[pos]
+	return g.gridBit(rc[0], rc[1])
+}
+
+// ---- 走法生成 ----
+
+// legalMovesBB 用位图找出所有合法 (棋串, 方向)，再交给 inlineGroupMove /
+// broadsideMove 生成 Modification；位图判定与二者的规则（含 RuleSet）一致。
+func (g *Game) legalMovesBB() []Move {
+	me := g.CurrentPlayer
+	own, foe := g.pieces[me], g.opponents(me)
+	free := g.onBoard
+	for p := int8(0); p < g.rules.Players; p++ {
+		free = free.andNot(g.pieces[p]) // 队友的子既不是空格也不能推
+	}
+	off := g.onBoard.not() // 棋盘外（含 VOID 外圈）
+
+	out := make([]Move, 0, 64)
+	emit := func(tails bitboard, step, size int, dir int8, inline bool) {
+		tails.each(func(t int) {
+			group := make([]int8, size)
+			for k := 0; k < size; k++ {
+				group[k] = g.gridPos[t+k*step]
+			}
+			if step < 0 { // 保持升序
+				for i, j := 0, size-1; i < j; i, j = i+1, j-1 {
+					group[i], group[j] = group[j], group[i]
+				}
+			}
+			var m Move
+			if inline {
+				m, _ = g.inlineGroupMove(group, dir)
+			} else {
+				m, _ = g.broadsideMove(group, dir)
+			}
+			out = append(out, m)
+		})
+	}
+
+	rs := &g.rules
+	maxLine := int(rs.MaxLine)
+	for dir := int8(0); dir < 6; dir++ {
+		o := g.dirShift[dir]
+		// run：以该格为队尾、沿 dir 连续 size 颗己方子
+		run := own
+		for size := 1; size <= maxLine; size++ {
+			if size > 1 {
+				run = run.and(own.ahead((size - 1) * o))
+			}
+			if run.empty() {
+				break
+			}
+			// 直线平移：队首前方为空
+			moves := run.and(free.ahead(size * o))
+			if rs.SelfEjectLoses { // 队首前方是棋盘外
+				moves = moves.or(run.and(off.ahead(size * o)))
+			}
+			// 推子：前方恰好 n 颗对手棋子（颜色可混合），再往前为空或棋盘外
+			for _, p := range rs.Pushes {
+				if int(p.Attackers) != size {
+					continue
+				}
+				n := int(p.Defenders)
+				push := run
+				for k := 0; k < n; k++ {
+					push = push.and(foe.ahead((size + k) * o))
+				}
+				moves = moves.or(push.and(free.or(off).ahead((size + n) * o)))
+			}
+			emit(moves, o, size, dir, true)
+		}
+	}
+	if !rs.Broadside {
+		return out
+	}
+
+	// 横移：2..MaxLine 子棋串只取三个正方向轴，目标格全部为空
+	for axis := 0; axis < 3; axis++ {
+		s := g.dirShift[axis]
+		for dir := int8(0); dir < 6; dir++ {
+			if int(dir)%3 == axis {
+				continue
+			}
+			o := g.dirShift[dir]
+			run, dst := own, free.ahead(o)
+			for size := 2; size <= maxLine; size++ {
+				run = run.and(own.ahead((size - 1) * s))
+				dst = dst.and(free.ahead((size-1)*s + o))
+				if run.empty() {
+					break
+				}
+				emit(run.and(dst), s, size, dir, false)
+			}
+		}
+	}
+	return out
+}
@@ -1,15 +1,22 @@
 // File internal/board/board.go
 package board
 
+import "abalone_go/internal/zobrist"
+
 const (
-	N          = 61 // 可落子格子数
+	N          = 61  // 标准棋盘（边长 5）的可落子格子数
+	MaxN       = 127 // 最大棋盘（边长 7）的可落子格子数
 	TokenVoid  = int8(-2)
 	TokenEmpty = int8(-1)
 	PlayerA    = int8(0)
 	PlayerB    = int8(1)
+	PlayerC    = int8(2) // 仅 3/4 人局
+	PlayerD    = int8(3) // 仅 4 人局
+
+	StandardSide = 5 // 标准棋盘边长
+	MaxSide      = 7
 
-	boardSize = 11
-	lifes     = 6
+	maxGrid = 2*MaxSide + 1 // 含 VOID 外圈的矩阵边长上限
 )
 
 // ACTIONS 六个方向 (dr, dc)
@@ -22,67 +29,103 @@ var ACTIONS = [6][2]int8{
 	{-1, 1}, // UP_RIGHT
 }
 
+// Game 对局状态。棋盘是边长 side 的六边形，放在 (2·side+1)² 的矩阵里，外圈为 VOID；
+// 标准边长 5 时与 python 版的 11×11 矩阵同形。
 type Game struct {
-	Cells           [boardSize][boardSize]int8 // 与 python 同形的 11×11 矩阵
-	posIndex        [N][2]int8                 // index -> (r,c)
-	coordIndex      [boardSize][boardSize]int8 // (r,c) -> index / -1
-	playerDamages   [2]int8
+	Cells           [maxGrid][maxGrid]int8 // 只用左上 grid×grid，其余为 VOID
+	posIndex        [MaxN][2]int8          // index -> (r,c)
+	coordIndex      [maxGrid][maxGrid]int8 // (r,c) -> index / -1
+	playerDamages   [MaxPlayers]int8       // 被推出数
+	captures        [MaxPlayers]int8       // 推出对手的棋子数
 	CurrentPlayer   int8
 	TurnCount       int
-	GameOver        bool
-	PlayerVictories [2]int
+	Result          Result // 零值为进行中
+	PlayerVictories [MaxPlayers]int
+
+	side      int8                    // 六边形边长
+	grid      int8                    // 矩阵边长 2·side+1
+	cells     int8                    // 可落子格子数 3·side·(side-1)+1
+	dirShift  [6]int                  // 六个方向在位图上的步长，顺序与 ACTIONS 一致
+	pieces    [MaxPlayers]bitboard    // 各方棋子位图，与 Cells 同步维护
+	onBoard   bitboard                // 可落子格
+	gridPos   [maxGrid * maxGrid]int8 // 位下标 -> index / -1
+	hash      uint64                  // Zobrist 哈希，Apply / Unmake 增量维护
+	history   []Undo                  // Play / TakeBack 使用的悔棋栈
+	positions []uint64                // 开局以来每个局面的哈希，用于判重复
+	rules     RuleSet                 // 本局规则
+	layout    [][]int8                // 开局摆法：每方的初始格子
+	variant   string                  // 开局名称；内置摆法为空
+	setup     [][]int8                // 自定义摆法（NewGameFromSetup，未计让子）；否则为 nil
+	handicap  Handicap                // 让子；layout 已去掉 Remove 中的棋子
 }
 
 // --------------------- 构造 & 初始化 ------------------------
 
-func NewGame(startPlayer int8) *Game {
-	g := &Game{}
+// NewGame 按 rules.Players / rules.BoardSide 对应的内置摆法开局；
+// rules 非法时 panic，来自外部输入的规则请先 Validate
+func NewGame(startPlayer int8, rules RuleSet) *Game {
+	return newGameFromLayout("", defaultLayout(rules.BoardSide, rules.Players), startPlayer, rules)
+}
+
+func newGameFromLayout(variant string, layout [][]int8, startPlayer int8, rules RuleSet) *Game {
+	if err := rules.Validate(); err != nil {
+		panic(err)
+	}
+	rules.Pushes = append([]PushRatio(nil), rules.Pushes...) // 与调用方脱钩
+	g := &Game{layout: layout, variant: variant, rules: rules}
 	g.initCoordTables()
-	g.reset(startPlayer)
+	g.Reset(startPlayer)
 	return g
 }
 
+// initCoordTables 按 g.rules.BoardSide 切出六边形并建立索引表
 func (g *Game) initCoordTables() {
+	s := g.rules.BoardSide
+	g.side, g.grid = s, 2*s+1
+	w := int(g.grid)
+	g.dirShift = [6]int{1, w, w - 1, -1, -w, -(w - 1)}
 	for r := range g.coordIndex {
 		for c := range g.coordIndex[r] {
 			g.coordIndex[r][c] = -1
 		}
 	}
+	for i := range g.gridPos {
+		g.gridPos[i] = -1
+	}
+	g.onBoard = bitboard{}
 
 	// 找空格位置，与 python 的 positions 顺序保持一致
 	idx := 0
-	for r := 0; r < boardSize; r++ {
-		for c := 0; c < boardSize; c++ {
+	for r := range g.Cells {
+		for c := range g.Cells[r] {
 			g.Cells[r][c] = TokenVoid // 默认都设 VOID
 		}
 	}
-	// 内部可下子区域：与 Python 的 new_board() 完全一致
-	for r := 1; r < boardSize-1; r++ {
-		for c := 1; c < boardSize-1; c++ {
-			// ① 先做大菱形裁剪
-			if r+c < 5 || r+c > 15 {
-				continue
-			}
-			// ② 再扣掉左上 4 层三角
-			if r <= 4 && c <= 5-r {
-				continue
-			}
-			// ③ 再扣掉右下 4 层三角
-			//    行 6→c≥9, 行 7→c≥8, 行 8→c≥7, 行 9→c≥6
-			if r >= 6 && c >= 15-r {
+	// 内部可下子区域：立方坐标 x=c-side, z=r-side, y=-x-z 三个分量都不超过 side-1；
+	// 边长 5 时与 Python 的 new_board() 完全一致
+	for r := int8(1); r < g.grid-1; r++ {
+		for c := int8(1); c < g.grid-1; c++ {
+			if y := -(c - s) - (r - s); y < 1-s || y > s-1 {
 				continue
 			}
 
 			g.coordIndex[r][c] = int8(idx)
-			g.posIndex[idx] = [2]int8{int8(r), int8(c)}
+			g.posIndex[idx] = [2]int8{r, c}
 			g.Cells[r][c] = TokenEmpty
+			g.gridPos[g.gridBit(r, c)] = int8(idx)
+			g.onBoard.set(g.gridBit(r, c))
 			idx++
 		}
 	}
-
+	g.cells = int8(idx)
 }
 
-func (g *Game) reset(startPlayer int8) {
+// Reset 按同一开局摆法、让子与规则重新开始一局，由 startPlayer 先走；
+// PlayerVictories 保留，可连续多局累计。startPlayer 超出人数时 panic
+func (g *Game) Reset(startPlayer int8) {
+	if startPlayer < 0 || startPlayer >= g.rules.Players {
+		panic("board: start player out of range")
+	}
 	// 清空棋盘
 	for r := range g.Cells {
 		for c := range g.Cells[r] {
@@ -91,22 +134,31 @@ func (g *Game) reset(startPlayer int8) {
 			}
 		}
 	}
-	// Classical 方案：黑白双方各 14 子
-	initialA := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}
-	initialB := []int{47, 48, 49, 50, 51, 52, 53, 54, 55, 56, 57, 58, 59, 60}
-	for _, p := range initialA {
-		r, c := g.posIndex[p][0], g.posIndex[p][1]
-		g.Cells[r][c] = PlayerA
-	}
-	for _, p := range initialB {
-		r, c := g.posIndex[p][0], g.posIndex[p][1]
-		g.Cells[r][c] = PlayerB
+	// 按开局摆法落子
+	for player, cells := range g.layout {
+		for _, p := range cells {
+			r, c := g.posIndex[p][0], g.posIndex[p][1]
+			g.Cells[r][c] = int8(player)
+		}
 	}
+	g.syncBitboards()
 
-	g.playerDamages = [2]int8{}
+	g.playerDamages = [MaxPlayers]int8{}
+	g.captures = g.handicap.HeadStart
 	g.CurrentPlayer = startPlayer
 	g.TurnCount = 1
-	g.GameOver = false
+	g.Result = Result{}
+	g.history = nil
+	g.rehash()
+	g.positions = []uint64{g.hash}
+}
+
+// Clone 深拷贝对局（含历史栈），副本可在另一个 goroutine 中独立 Make / Unmake
+func (g *Game) Clone() *Game {
+	c := *g
+	c.history = append([]Undo(nil), g.history...)
+	c.positions = append([]uint64(nil), g.positions...)
+	return &c
 }
 
 // -------------------- 公共工具 -----------------------------
@@ -115,7 +167,21 @@ func (g *Game) reset(startPlayer int8) {
 func (g *Game) PosToCoord(pos int8) (int8, int8) { rc := g.posIndex[pos]; return rc[0], rc[1] }
 
 // CoordToPos 若坐标非法返回 -1
-func (g *Game) CoordToPos(r, c int8) int8 { return g.coordIndex[r][c] }
+func (g *Game) CoordToPos(r, c int8) int8 {
+	if r < 0 || c < 0 || r >= maxGrid || c >= maxGrid {
+		return -1
+	}
+	return g.coordIndex[r][c]
+}
+
+// cellsForSide 边长 side 的六边形格子数
+func cellsForSide(side int8) int { return 3*int(side)*(int(side)-1) + 1 }
+
+// Side 返回棋盘边长（标准为 5）
+func (g *Game) Side() int8 { return g.side }
+
+// CellCount 返回可落子格子数：边长 5/6/7 依次为 61/91/127
+func (g *Game) CellCount() int8 { return g.cells }
 
 // TokenAt 直接读棋子
 func (g *Game) TokenAt(pos int8) int8 {
@@ -123,25 +189,39 @@ func (g *Game) TokenAt(pos int8) int8 {
 	return g.Cells[r][c]
 }
 
+// Hash 返回当前局面的 Zobrist 哈希：棋子、行棋方与各方被推出数 / 吃子数。
+// 直接改写 CurrentPlayer 不会同步哈希，让一手请用 MakeNull。
+func (g *Game) Hash() uint64 { return g.hash }
+
+// rehash 从头计算哈希；整盘改写后调用
+func (g *Game) rehash() {
+	var cells [MaxN]int8
+	for p := int8(0); p < g.cells; p++ {
+		cells[p] = g.TokenAt(p)
+	}
+	n := g.rules.Players
+	g.hash = zobrist.Hash(cells[:g.cells], g.CurrentPlayer, g.playerDamages[:n], g.captures[:n])
+}
+
+// Variant 返回开局名称；内置摆法返回空串
+func (g *Game) Variant() string { return g.variant }
+
+// Damages 返回玩家已被推出的棋子数
+func (g *Game) Damages(player int8) int8 { return g.playerDamages[player] }
+
 // PlayerPieces 返回玩家当前在棋盘上的棋子数量（实时统计）
 func (g *Game) PlayerPieces(player int8) int8 {
-	var cnt int8
-	for pos := int8(0); pos < N; pos++ {
-		if g.TokenAt(pos) == player {
-			cnt++
-		}
-	}
-	return cnt
+	return int8(g.pieces[player].count())
 }
 
 // IsEdge 返回 (r,c) 是否位于环状“最外一圈”——也就是
 //
-//	r==0/10 || c==0/10 || r==1/9 || c==1/9 但并非 VOID。
+//	r==1/grid-2 || c==1/grid-2 但并非 VOID（标准棋盘 grid=11）。
 func (g *Game) IsEdge(r, c int8) bool {
 	if g.CoordToPos(r, c) == -1 { // VOID/越界
 		return false
 	}
-	return r <= 1 || r >= 9 || c <= 1 || c >= 9
+	return r <= 1 || r >= g.grid-2 || c <= 1 || c >= g.grid-2
 }
 
 // NeighborCoords 返回 6 个方向相邻坐标 (越界仍给出，可配合 CoordToPos 判断)
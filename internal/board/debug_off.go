@@ -0,0 +1,7 @@
+//go:build !debug
+
+// File internal/board/debug_off.go
+package board
+
+// debugChecks 默认关闭；见 debug_on.go
+const debugChecks = false
@@ -0,0 +1,7 @@
+//go:build debug
+
+// File internal/board/debug_on.go
+package board
+
+// debugChecks 以 -tags debug 构建时打开：每次 Apply 之后调用 Validate，不自洽立即 panic
+const debugChecks = true
@@ -0,0 +1,79 @@
+// File internal/board/errors.go
+package board
+
+import "fmt"
+
+// Reason 走法被拒绝的原因
+type Reason uint8
+
+const (
+	ReasonNone               Reason = iota
+	ReasonNotOwnMarble              // 棋串里有不属于自己的格子
+	ReasonNotInLine                 // 棋子不相连或不共线
+	ReasonGroupTooLarge             // 超过 RuleSet.MaxLine 颗
+	ReasonNotOutnumbered            // 推子人数不占优：1v1、2v2、3v3……
+	ReasonBlockedByOwn              // 前进路线被己方棋子挡住
+	ReasonDestinationNotFree        // 目标格已被占
+	ReasonOffBoard                  // 会把自己的子走出棋盘
+	ReasonBadDirection              // 方向非法 / 两点不构成任何走法
+	ReasonGameOver                  // 对局已结束
+	ReasonNotAllowed                // 当前规则不允许（如关闭侧移）
+)
+
+var reasonText = [...]string{
+	ReasonNone:               "ok",
+	ReasonNotOwnMarble:       "not your marble",
+	ReasonNotInLine:          "marbles are not in a connected line",
+	ReasonGroupTooLarge:      "too many marbles in the group",
+	ReasonNotOutnumbered:     "not enough marbles to push (no 1v1, 2v2, 3v3)",
+	ReasonBlockedByOwn:       "blocked by your own marble",
+	ReasonDestinationNotFree: "destination is not free",
+	ReasonOffBoard:           "cannot move your own marble off the board",
+	ReasonBadDirection:       "no move in that direction",
+	ReasonGameOver:           "game is over",
+	ReasonNotAllowed:         "not allowed by the current rules",
+}
+
+func (r Reason) String() string {
+	if int(r) < len(reasonText) {
+		return reasonText[r]
+	}
+	return fmt.Sprintf("reason(%d)", r)
+}
+
+// MoveError 非法走法，用 errors.As 取出原因
+type MoveError struct {
+	Reason  Reason
+	Pos     int8   // 出问题的格子；无法定位时为 -1
+	Cell    string // Pos 的标准记法，如 "A1"
+	MaxLine int8   // 本局的 RuleSet.MaxLine，用于 ReasonGroupTooLarge 的提示；0 表示未知
+}
+
+func (e *MoveError) Error() string {
+	if e.Cell != "" {
+		return fmt.Sprintf("illegal move at %s: %s", e.Cell, e.message())
+	}
+	return "illegal move: " + e.message()
+}
+
+// message 原因说明；棋串过长时按本局规则给出上限
+func (e *MoveError) message() string {
+	if e.Reason == ReasonGroupTooLarge && e.MaxLine > 0 {
+		return fmt.Sprintf("a group may move at most %d marbles", e.MaxLine)
+	}
+	return e.Reason.String()
+}
+
+// moveErr 构造 MoveError；r 为 ReasonNone 时返回 nil
+func (g *Game) moveErr(r Reason, pos int8) error {
+	if r == ReasonNone {
+		return nil
+	}
+	e := &MoveError{Reason: r, Pos: pos, MaxLine: g.rules.MaxLine}
+	if pos >= 0 && pos < g.cells {
+		e.Cell = g.CellName(pos)
+	} else {
+		e.Pos = -1
+	}
+	return e
+}
@@ -0,0 +1,230 @@
+// File internal/board/handicap.go
+package board
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"abalone_go/internal/zobrist"
+)
+
+// ---- 自定义摆法 ----
+
+// Setup 开局摆法：每方的初始格子（格子编号与 posIndex 顺序一致），
+// 与 variants.json 中 players_sets 同形，如 [[0,1,2],[58,59,60]]
+type Setup [][]int8
+
+// ParseSetup 解析 JSON 形式的摆法
+func ParseSetup(s string) (Setup, error) {
+	var setup Setup
+	if err := json.Unmarshal([]byte(s), &setup); err != nil {
+		return nil, fmt.Errorf("board: setup: %w", err)
+	}
+	return setup, nil
+}
+
+// String 输出可被 ParseSetup 读回的 JSON
+func (s Setup) String() string {
+	b, _ := json.Marshal([][]int8(s))
+	return string(b)
+}
+
+// checkLayout 每方 1..zobrist.MaxDamage 子（被推出数的哈希键只有这么多），
+// 格子在棋盘内且互不重复，方数与规则一致
+func checkLayout(layout [][]int8, rules RuleSet) error {
+	if len(layout) != int(rules.Players) {
+		return fmt.Errorf("%d player sets for a %d-player game", len(layout), rules.Players)
+	}
+	cells := cellsForSide(rules.BoardSide)
+	seen := [MaxN]bool{}
+	for player, set := range layout {
+		if len(set) == 0 {
+			return fmt.Errorf("%c has no marbles", 'A'+player)
+		}
+		if len(set) > zobrist.MaxDamage {
+			return fmt.Errorf("%c has %d marbles, at most %d", 'A'+player, len(set), zobrist.MaxDamage)
+		}
+		for _, p := range set {
+			if p < 0 || int(p) >= cells || seen[p] {
+				return fmt.Errorf("invalid cell %d", p)
+			}
+			seen[p] = true
+		}
+	}
+	return nil
+}
+
+// NewGameFromSetup 按自定义摆法开局；setup[i] 归第 i 位玩家，
+// 个数须与 rules.Players 一致，格子须在 rules.BoardSide 的棋盘内
+func NewGameFromSetup(setup Setup, startPlayer int8, rules RuleSet) (*Game, error) {
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	if err := checkStartPlayer(startPlayer, rules); err != nil {
+		return nil, err
+	}
+	if err := checkLayout(setup, rules); err != nil {
+		return nil, fmt.Errorf("board: setup: %w", err)
+	}
+	layout := make([][]int8, len(setup))
+	for i, set := range setup {
+		layout[i] = append([]int8(nil), set...)
+	}
+	g := newGameFromLayout("", layout, startPlayer, rules)
+	g.setup = layout
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Setup 自定义摆法（未计让子）；内置摆法与命名开局返回 nil
+func (g *Game) Setup() Setup {
+	if g.setup == nil {
+		return nil
+	}
+	out := make(Setup, len(g.setup))
+	for i, set := range g.setup {
+		out[i] = append([]int8(nil), set...)
+	}
+	return out
+}
+
+// ---- 让子 ----
+
+// Handicap 让子，开局时生效，Reset 之后依然有效：
+//
+//	Remove     开局前从这些格子拿掉棋子（通常是强方的），这一方的总子数随之减少
+//	HeadStart  HeadStart[p] 计入 p 的吃子数，相当于开局前已推出这么多颗
+//
+// 文本形式如 "remove=A1,A5 headstart=0-2"，见 ParseHandicap。
+type Handicap struct {
+	Remove    []int8
+	HeadStart [MaxPlayers]int8
+}
+
+// IsZero 是否没有任何让子
+func (h Handicap) IsZero() bool {
+	return len(h.Remove) == 0 && h.HeadStart == [MaxPlayers]int8{}
+}
+
+// Handicap 本局的让子
+func (g *Game) Handicap() Handicap {
+	h := g.handicap
+	h.Remove = append([]int8(nil), h.Remove...)
+	return h
+}
+
+// HeadStart 让子中预先记给 p 的吃子数
+func (g *Game) HeadStart(p int8) int8 { return g.handicap.HeadStart[p] }
+
+// ApplyHandicap 对尚未走棋的对局施加让子并重新开局（行棋方不变）。
+// 拿掉的格子必须有子且不能拿光一方；任何一队的 HeadStart 合计须少于 MarblesToWin
+func (g *Game) ApplyHandicap(h Handicap) error {
+	if g.TurnCount != 1 || len(g.history) > 0 || len(g.positions) > 1 {
+		return fmt.Errorf("board: handicap: game has already started")
+	}
+	if !g.handicap.IsZero() {
+		return fmt.Errorf("board: handicap: already applied")
+	}
+	layout := make([][]int8, len(g.layout))
+	for i, set := range g.layout {
+		layout[i] = append([]int8(nil), set...)
+	}
+	for _, p := range h.Remove {
+		owner := -1
+		for player, set := range layout {
+			for i, q := range set {
+				if q == p {
+					owner = player
+					layout[player] = append(set[:i:i], set[i+1:]...)
+					break
+				}
+			}
+		}
+		switch {
+		case p < 0 || p >= g.cells:
+			return fmt.Errorf("board: handicap: invalid cell %d", p)
+		case owner < 0:
+			return fmt.Errorf("board: handicap: no marble on %s", g.CellName(p))
+		case len(layout[owner]) == 0:
+			return fmt.Errorf("board: handicap: removes every %c marble", 'A'+owner)
+		}
+	}
+	for p := int8(0); p < MaxPlayers; p++ {
+		if h.HeadStart[p] < 0 || (p >= g.rules.Players && h.HeadStart[p] != 0) {
+			return fmt.Errorf("board: handicap: bad head start for %c", 'A'+p)
+		}
+		var team int8
+		for q := int8(0); q < g.rules.Players; q++ {
+			if g.Team(q) == g.Team(p) {
+				team += h.HeadStart[q]
+			}
+		}
+		if p < g.rules.Players && team >= g.rules.MarblesToWin {
+			return fmt.Errorf("board: handicap: head start %d would already win", team)
+		}
+	}
+
+	t := *g
+	t.layout = layout
+	t.handicap = h
+	t.handicap.Remove = append([]int8(nil), h.Remove...)
+	t.Reset(g.CurrentPlayer)
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	*g = t
+	return nil
+}
+
+// FormatHandicap 输出让子的文本形式，可被 ParseHandicap 读回；没有让子时为空串
+func (g *Game) FormatHandicap(h Handicap) string {
+	var fields []string
+	if len(h.Remove) > 0 {
+		names := make([]string, len(h.Remove))
+		for i, p := range h.Remove {
+			names[i] = g.CellName(p)
+		}
+		fields = append(fields, "remove="+strings.Join(names, ","))
+	}
+	if h.HeadStart != [MaxPlayers]int8{} {
+		fields = append(fields, "headstart="+joinCounts(h.HeadStart[:g.rules.Players]))
+	}
+	return strings.Join(fields, " ")
+}
+
+// ParseHandicap 解析 "remove=A1,A5 headstart=0-2"：remove 为要拿掉的格子，
+// headstart 为每方开局已有的吃子数（个数与人数相同）；两项都可省略
+func (g *Game) ParseHandicap(s string) (Handicap, error) {
+	var h Handicap
+	for _, field := range strings.Fields(s) {
+		key, val, ok := strings.Cut(field, "=")
+		var err error
+		switch {
+		case !ok:
+			err = fmt.Errorf("want key=value")
+		case key == "remove":
+			for _, name := range strings.Split(val, ",") {
+				var p int8
+				if p, err = g.ParseCell(name); err != nil {
+					break
+				}
+				h.Remove = append(h.Remove, p)
+			}
+		case key == "headstart":
+			var n int8
+			h.HeadStart, n, err = parseCounts(val)
+			if err == nil && n != g.rules.Players {
+				err = fmt.Errorf("want %d counts", g.rules.Players)
+			}
+		default:
+			err = fmt.Errorf("unknown key")
+		}
+		if err != nil {
+			return Handicap{}, fmt.Errorf("board: handicap: bad field %q: %v", field, err)
+		}
+	}
+	return h, nil
+}
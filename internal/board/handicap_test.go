@@ -0,0 +1,49 @@
+// File internal/board/handicap_test.go
+package board
+
+import (
+	"math/rand"
+	"testing"
+
+	"abalone_go/internal/zobrist"
+)
+
+// cellRange 返回 [from, to) 的格子
+func cellRange(from, to int8) []int8 {
+	out := make([]int8, 0, to-from)
+	for p := from; p < to; p++ {
+		out = append(out, p)
+	}
+	return out
+}
+
+// TestSetupMarbleLimit 一方超过 zobrist.MaxDamage 子的摆法必须拒绝：
+// 否则被推出第 21 颗时 Apply 取被推出数的哈希键越界
+func TestSetupMarbleLimit(t *testing.T) {
+	rules, err := ParseRules("players=3 win=15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int8{40, zobrist.MaxDamage + 1} {
+		setup := Setup{cellRange(0, n), cellRange(n, n+5), cellRange(n+5, n+10)}
+		if _, err := NewGameFromSetup(setup, PlayerA, rules); err == nil {
+			t.Errorf("setup with %d A marbles accepted", n)
+		}
+	}
+
+	// 恰好 MaxDamage 子可以，随机下到终局也不越界
+	n := int8(zobrist.MaxDamage)
+	setup := Setup{cellRange(0, n), cellRange(n, n+5), cellRange(n+5, n+10)}
+	g, err := NewGameFromSetup(setup, PlayerA, rules)
+	if err != nil {
+		t.Fatalf("setup with %d A marbles: %v", n, err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	for ply := 0; ply < 500 && !g.Result.Over(); ply++ {
+		moves := LegalMoves(g)
+		if len(moves) == 0 {
+			break
+		}
+		g.Play(moves[rng.Intn(len(moves))])
+	}
+}
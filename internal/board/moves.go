@@ -0,0 +1,290 @@
+// File internal/board/moves.go
+package board
+
+import "sort"
+
+// ---------------- 走法类型 -----------------
+
+// MoveKind 走法类型，String() 与 ValidateMove 返回的字符串一致
+type MoveKind uint8
+
+const (
+	KindInlineMove   MoveKind = iota // 直线平移
+	KindSidestepMove                 // 侧移（broadside）
+	KindInlinePush                   // 推子（未推出）
+	KindEjected                      // 推子并推出一颗
+	KindWinner                       // 推出后对手达到失败条件
+	KindSelfEject                    // 把己方领头子走出棋盘并判负（RuleSet.SelfEjectLoses）
+)
+
+var kindNames = [...]string{
+	KindInlineMove:   "inline_move",
+	KindSidestepMove: "sidestep_move",
+	KindInlinePush:   "inline_push",
+	KindEjected:      "ejected",
+	KindWinner:       "winner",
+	KindSelfEject:    "self_eject",
+}
+
+func (k MoveKind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "unknown"
+}
+
+// IsPush 是否为推子（含推出）
+func (k MoveKind) IsPush() bool {
+	return k == KindInlinePush || k == KindEjected || k == KindWinner
+}
+
+// Move 一步完整走法：己方棋串 + 方向 + 类型 + 对应的盘面修改
+type Move struct {
+	Group []int8 // 参与移动的己方棋子，沿棋串方向升序排列
+	Dir   int8   // ACTIONS 下标 0-5
+	Kind  MoveKind
+	Mods  []Modification // 可直接交给 Apply
+}
+
+// ---------------- 走法生成 -----------------
+
+// LegalMoves 返回当前玩家所有合法走法：直线平移、侧移与推子。
+// 用位图一次筛出全部合法的“棋串 × 方向”，每种走法只出现一次。
+func LegalMoves(g *Game) []Move {
+	return g.legalMovesBB()
+}
+
+// legalMovesSlow 逐个棋串 × 方向调用 inlineGroupMove / broadsideMove 的朴素生成器，
+// 与 legalMovesBB 结果相同；留作位图版本的对照（见 moves_test.go）
+func (g *Game) legalMovesSlow() []Move {
+	out := make([]Move, 0, 64)
+	me := g.CurrentPlayer
+	for pos := int8(0); pos < g.cells; pos++ {
+		if g.TokenAt(pos) != me {
+			continue
+		}
+
+		// ① 单子：六个方向都算直线平移
+		for dir := int8(0); dir < 6; dir++ {
+			if m, rj := g.inlineGroupMove([]int8{pos}, dir); rj.ok() {
+				out = append(out, m)
+			}
+		}
+
+		// ② 2..MaxLine 子串：只沿三个正方向延伸，保证每个棋串只生成一次
+		for axis := int8(0); axis < 3; axis++ {
+			group := []int8{pos}
+			for size := int8(2); size <= g.rules.MaxLine; size++ {
+				next := g.step(group[len(group)-1], axis)
+				if next < 0 || g.TokenAt(next) != me {
+					break
+				}
+				group = append(group[:size-1:size-1], next)
+
+				for dir := int8(0); dir < 6; dir++ {
+					var m Move
+					var rj rejection
+					switch {
+					case dir%3 == axis:
+						m, rj = g.inlineGroupMove(group, dir)
+					case !g.rules.Broadside:
+						continue
+					default:
+						m, rj = g.broadsideMove(group, dir)
+					}
+					if rj.ok() {
+						out = append(out, m)
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// rejection 内部使用的拒绝原因，零值表示合法；避免走法生成时为每个非法候选分配 error
+type rejection struct {
+	reason Reason
+	pos    int8
+}
+
+func (rj rejection) ok() bool { return rj.reason == ReasonNone }
+
+func (g *Game) rejectErr(rj rejection) error { return g.moveErr(rj.reason, rj.pos) }
+
+// GroupMove 用“棋串 + 方向”明确指定一步：group 为 1..MaxLine 颗相连共线的己方棋子（顺序任意），
+// dir 为 ACTIONS 下标。dir 与棋串同轴时为直线平移/推子，否则为侧移。
+// 每个合法走法恰好对应一组 (group, dir)，不存在两点输入的歧义。
+// 非法时返回 *MoveError。
+func (g *Game) GroupMove(group []int8, dir int8) (Move, error) {
+	if g.Result.Over() {
+		return Move{}, g.moveErr(ReasonGameOver, -1)
+	}
+	sorted, axis, rj := g.groupAxis(group)
+	if !rj.ok() {
+		return Move{}, g.rejectErr(rj)
+	}
+	if dir < 0 || dir >= 6 {
+		return Move{}, g.moveErr(ReasonBadDirection, -1)
+	}
+	var m Move
+	switch {
+	case axis < 0 || dir%3 == axis:
+		m, rj = g.inlineGroupMove(sorted, dir)
+	case !g.rules.Broadside:
+		rj = rejection{ReasonNotAllowed, sorted[0]}
+	default:
+		m, rj = g.broadsideMove(sorted, dir)
+	}
+	return m, g.rejectErr(rj)
+}
+
+// IsGroup group 是否为 1..MaxLine 颗相连共线的己方棋子
+func (g *Game) IsGroup(group []int8) bool {
+	_, _, rj := g.groupAxis(group)
+	return rj.ok()
+}
+
+// groupAxis 校验棋串，返回升序副本与所在轴 0-2（单子为 -1）
+func (g *Game) groupAxis(group []int8) ([]int8, int8, rejection) {
+	if len(group) == 0 {
+		return nil, -1, rejection{ReasonNotOwnMarble, -1}
+	}
+	if len(group) > int(g.rules.MaxLine) {
+		return nil, -1, rejection{ReasonGroupTooLarge, group[g.rules.MaxLine]}
+	}
+	sorted := append([]int8(nil), group...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for i, p := range sorted {
+		if p < 0 || p >= g.cells || g.TokenAt(p) != g.CurrentPlayer {
+			return nil, -1, rejection{ReasonNotOwnMarble, p}
+		}
+		if i > 0 && p == sorted[i-1] {
+			return nil, -1, rejection{ReasonNotInLine, p}
+		}
+	}
+	if len(sorted) == 1 {
+		return sorted, -1, rejection{}
+	}
+
+	// 升序排列后相邻两颗必在某个正方向上
+	axis := g.neighborDir(sorted[0], sorted[1])
+	if axis < 0 || axis >= 3 {
+		return nil, -1, rejection{ReasonNotInLine, sorted[1]}
+	}
+	for i := 2; i < len(sorted); i++ {
+		if g.step(sorted[i-1], axis) != sorted[i] {
+			return nil, -1, rejection{ReasonNotInLine, sorted[i]}
+		}
+	}
+	return sorted, axis, rejection{}
+}
+
+// inlineGroupMove 棋串沿自身方向移动一格；前方是敌子时按 Sumito 规则推子。
+// group 沿 axis 正方向升序，dir 为 axis 或其反方向。
+func (g *Game) inlineGroupMove(group []int8, dir int8) (Move, rejection) {
+	me := g.CurrentPlayer
+	dr, dc := ACTIONS[dir][0], ACTIONS[dir][1]
+
+	// 领头子：沿 dir 方向最前面的那颗
+	head := group[len(group)-1]
+	if dir >= 3 {
+		head = group[0]
+	}
+	hr, hc := g.PosToCoord(head)
+	nr, nc := hr+dr, hc+dc
+
+	size := int8(len(group))
+	switch g.Cells[nr][nc] {
+	case TokenEmpty:
+		return Move{
+			Group: group,
+			Dir:   dir,
+			Kind:  KindInlineMove,
+			Mods:  g.chainMods(hr, hc, dr, dc, size, dir),
+		}, rejection{}
+	case TokenVoid:
+		if !g.rules.SelfEjectLoses {
+			return Move{}, rejection{ReasonOffBoard, head}
+		}
+		mods := []Modification{{OldPos: head, NewPos: -1, DirIndex: -1, Piece: me}}
+		mods = append(mods, g.chainMods(hr-dr, hc-dc, dr, dc, size-1, dir)...)
+		return Move{Group: group, Dir: dir, Kind: KindSelfEject, Mods: mods}, rejection{}
+	}
+	if !g.IsOpponent(me, g.Cells[nr][nc]) { // 己子或队友
+		return Move{}, rejection{ReasonBlockedByOwn, g.CoordToPos(nr, nc)}
+	}
+
+	// ---- 推子：数连续敌子（多人局可以是不同对手的混合）----
+	var nEnemies int8
+	er, ec := nr, nc
+	for g.IsOpponent(me, g.Cells[er][ec]) {
+		nEnemies++
+		er, ec = er+dr, ec+dc
+	}
+	if !g.rules.canPush(size, nEnemies) {
+		return Move{}, rejection{ReasonNotOutnumbered, g.CoordToPos(nr, nc)}
+	}
+	if g.Cells[er][ec] != TokenEmpty && g.Cells[er][ec] != TokenVoid {
+		return Move{}, rejection{ReasonBlockedByOwn, g.CoordToPos(er, ec)}
+	}
+
+	kind := KindInlinePush
+	var mods []Modification
+	lr, lc := er-dr, ec-dc // 最后一颗敌子
+	total := size + nEnemies
+	if g.Cells[er][ec] == TokenVoid {
+		victim := g.Cells[lr][lc]
+		kind = KindEjected
+		if g.TeamCaptures(me)+1 == g.rules.MarblesToWin {
+			kind = KindWinner
+		}
+		mods = append(mods, Modification{
+			OldPos: g.CoordToPos(lr, lc), NewPos: -1, DirIndex: -1, Piece: victim,
+		})
+		lr, lc = lr-dr, lc-dc
+		total--
+	}
+	mods = append(mods, g.chainMods(lr, lc, dr, dc, total, dir)...)
+
+	return Move{Group: group, Dir: dir, Kind: kind, Mods: mods}, rejection{}
+}
+
+// chainMods 从最前一颗 (r,c) 往回数 n 颗，每颗沿 (dr,dc) 前进一格。
+// 由前往后排列，保证 Apply 顺序执行时不会互相覆盖。
+func (g *Game) chainMods(r, c, dr, dc, n, dir int8) []Modification {
+	mods := make([]Modification, 0, n)
+	for k := int8(0); k < n; k++ {
+		rr, cc := r-k*dr, c-k*dc
+		mods = append(mods, Modification{
+			OldPos:   g.CoordToPos(rr, cc),
+			NewPos:   g.CoordToPos(rr+dr, cc+dc),
+			DirIndex: dir,
+			Piece:    g.Cells[rr][cc],
+		})
+	}
+	return mods
+}
+
+// broadsideMove 2/3 子串整体侧移一格，所有目标格必须为空。
+func (g *Game) broadsideMove(group []int8, dir int8) (Move, rejection) {
+	dr, dc := ACTIONS[dir][0], ACTIONS[dir][1]
+	mods := make([]Modification, 0, len(group))
+	for _, p := range group {
+		r, c := g.PosToCoord(p)
+		switch g.Cells[r+dr][c+dc] {
+		case TokenEmpty:
+		case TokenVoid:
+			return Move{}, rejection{ReasonOffBoard, p}
+		default:
+			return Move{}, rejection{ReasonDestinationNotFree, g.CoordToPos(r+dr, c+dc)}
+		}
+		mods = append(mods, Modification{
+			OldPos:   p,
+			NewPos:   g.CoordToPos(r+dr, c+dc),
+			DirIndex: dir,
+			Piece:    g.CurrentPlayer,
+		})
+	}
+	return Move{Group: group, Dir: dir, Kind: KindSidestepMove, Mods: mods}, rejection{}
+}
@@ -0,0 +1,158 @@
+// File internal/board/moves_test.go
+package board
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// testRuleSets 覆盖各种棋盘、人数与规则开关
+var testRuleSets = []string{
+	"",
+	"broadside=off",
+	"line=4 push=2:1,3:1,3:2,4:1,4:2,4:3",
+	"selfeject=on",
+	"side=6",
+	"side=7 line=5 push=2:1,3:1,3:2,5:4",
+	"players=3 win=4",
+	"players=4 win=4 selfeject=on",
+}
+
+// randomPositions 按每种规则随机下 games 局，每局至多 plies 手，对途经的每个局面调用 fn；
+// fn 必须让 g 保持原状
+func randomPositions(t *testing.T, games, plies int, fn func(g *Game)) {
+	t.Helper()
+	rng := rand.New(rand.NewSource(1))
+	for _, s := range testRuleSets {
+		rules, err := ParseRules(s)
+		if err != nil {
+			t.Fatalf("ParseRules(%q): %v", s, err)
+		}
+		for i := 0; i < games; i++ {
+			g := NewGame(int8(rng.Intn(int(rules.Players))), rules)
+			for ply := 0; ply < plies && !g.Result.Over(); ply++ {
+				fn(g)
+				moves := LegalMoves(g)
+				if len(moves) == 0 {
+					break
+				}
+				g.Play(moves[rng.Intn(len(moves))])
+			}
+		}
+	}
+}
+
+// moveKey 用 (棋串, 方向) 标识一步
+func moveKey(m Move) string { return fmt.Sprint(m.Group, m.Dir) }
+
+// afterMove 走一步后的局面、哈希与走法类型，走完即撤回
+func afterMove(g *Game, m Move) string {
+	u := g.Make(m)
+	s := fmt.Sprintf("%s %s %#x", g, m.Kind, g.Hash())
+	g.Unmake(u)
+	return s
+}
+
+// movesByKey 按 (棋串, 方向) 收集走法结果，重复时报错
+func movesByKey(t *testing.T, g *Game, moves []Move, who string) map[string]string {
+	t.Helper()
+	out := make(map[string]string, len(moves))
+	for _, m := range moves {
+		k := moveKey(m)
+		if _, dup := out[k]; dup {
+			t.Fatalf("%s: %s: duplicate move %s", g, who, k)
+		}
+		out[k] = afterMove(g, m)
+	}
+	return out
+}
+
+// TestLegalMovesBB 位图生成器与逐棋串生成器给出同样的走法、局面与哈希
+func TestLegalMovesBB(t *testing.T) {
+	randomPositions(t, 4, 120, func(g *Game) {
+		fast := movesByKey(t, g, g.legalMovesBB(), "legalMovesBB")
+		slow := movesByKey(t, g, g.legalMovesSlow(), "legalMovesSlow")
+		for k, want := range slow {
+			if got, ok := fast[k]; !ok {
+				t.Fatalf("%s: legalMovesBB misses %s", g, k)
+			} else if got != want {
+				t.Fatalf("%s: move %s: legalMovesBB gives %s, legalMovesSlow gives %s", g, k, got, want)
+			}
+		}
+		for k := range fast {
+			if _, ok := slow[k]; !ok {
+				t.Fatalf("%s: legalMovesBB has extra move %s", g, k)
+			}
+		}
+	})
+}
+
+// TestGroupMove GroupMove 接受的 (棋串, 方向) 与 LegalMoves 一一对应、结果各不相同，
+// 两点输入 ValidateMove 能走出的局面也都有对应的 GroupMove
+func TestGroupMove(t *testing.T) {
+	randomPositions(t, 2, 100, func(g *Game) {
+		legal := movesByKey(t, g, LegalMoves(g), "LegalMoves")
+		results := make(map[string]string, len(legal))
+		for k, after := range legal {
+			// 自杀走法只是让队尾那格变空，同一队尾的几种走法结果相同
+			if other, dup := results[after]; dup && !strings.Contains(after, KindSelfEject.String()) {
+				t.Fatalf("%s: moves %s and %s reach the same position", g, k, other)
+			}
+			results[after] = k
+		}
+
+		// 枚举所有相连共线的己方棋串，倒序传入以检验顺序无关
+		accepted := 0
+		for pos := int8(0); pos < g.cells; pos++ {
+			if g.TokenAt(pos) != g.CurrentPlayer {
+				continue
+			}
+			for axis := int8(0); axis < 3; axis++ {
+				group := []int8{pos}
+				for {
+					rev := make([]int8, len(group))
+					for i, p := range group {
+						rev[len(group)-1-i] = p
+					}
+					for dir := int8(-1); dir <= 6 && (len(group) > 1 || axis == 0); dir++ { // 单子只在 axis 0 试一次
+						m, err := g.GroupMove(rev, dir)
+						if err != nil {
+							continue
+						}
+						k := moveKey(m)
+						if want, ok := legal[k]; !ok {
+							t.Fatalf("%s: GroupMove(%v, %d) = %s, not in LegalMoves", g, rev, dir, k)
+						} else if got := afterMove(g, m); got != want {
+							t.Fatalf("%s: GroupMove %s gives %s, LegalMoves gives %s", g, k, got, want)
+						}
+						accepted++
+					}
+					next := g.step(group[len(group)-1], axis)
+					if next < 0 || g.TokenAt(next) != g.CurrentPlayer || len(group) == int(g.rules.MaxLine) {
+						break
+					}
+					group = append(group, next)
+				}
+			}
+		}
+		if accepted != len(legal) {
+			t.Fatalf("%s: GroupMove accepts %d moves, LegalMoves has %d", g, accepted, len(legal))
+		}
+
+		for p0 := int8(0); p0 < g.cells; p0++ {
+			for p1 := int8(0); p1 < g.cells; p1++ {
+				m, err := g.MoveFromPair(p0, p1)
+				if err != nil {
+					continue
+				}
+				after := afterMove(g, m)
+				if _, ok := results[after]; !ok {
+					t.Fatalf("%s: ValidateMove(%s, %s) reaches %s, no GroupMove does",
+						g, g.CellName(p0), g.CellName(p1), after)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,215 @@
+// File internal/board/notation.go
+package board
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 标准 Abalone 坐标：行用字母 A..I（A 在最下方，即 posIndex 的最后一行），
+// 斜列用数字 1..9（与 11×11 矩阵的列号相同）。A1..A5 在底边，I5..I9 在顶边。
+// 大棋盘照此延伸：边长 6 为 A..K / 1..11，边长 7 为 A..M / 1..13，列号可以是两位数。
+//
+// 走法记法：
+//
+//	直线平移 / 推子：尾子 + 尾子落点，如 "A1B2"（整串沿 A1→B2 方向前进一格）
+//	侧移：首子 + 末子 + 首子落点，如 "A1A3B2"
+//	走出己子（仅 RuleSet.SelfEjectLoses）：尾子 + "x" + ACTIONS 方向下标，如 "A1x3"
+//
+// 首/末子按格子编号升序；直线走法的棋串由尾子沿方向连续延伸的己方棋子构成。
+
+// CellName 把格子索引转为 "A1" 形式
+func (g *Game) CellName(pos int8) string {
+	r, c := g.PosToCoord(pos)
+	return fmt.Sprintf("%c%d", 'A'+(g.grid-2-r), c)
+}
+
+// ParseCell 解析 "A1" 形式坐标（大小写均可）
+func (g *Game) ParseCell(s string) (int8, error) {
+	if len(s) != 2 && len(s) != 3 {
+		return -1, fmt.Errorf("board: bad cell %q", s)
+	}
+	row := int8(strings.ToUpper(s[:1])[0]) - 'A'
+	col, err := strconv.Atoi(s[1:])
+	r := g.grid - 2 - row
+	if err != nil || s[1] == '0' || row < 0 || r < 1 || col < 1 || col > int(g.grid-2) {
+		return -1, fmt.Errorf("board: bad cell %q", s)
+	}
+	pos := g.CoordToPos(r, int8(col))
+	if pos < 0 {
+		return -1, fmt.Errorf("board: cell %q is off the board", s)
+	}
+	return pos, nil
+}
+
+// FormatMove 按标准记法输出走法
+func (g *Game) FormatMove(m Move) string {
+	first, last := m.Group[0], m.Group[len(m.Group)-1]
+	if m.Kind == KindSidestepMove {
+		return g.CellName(first) + g.CellName(last) + g.CellName(g.step(first, m.Dir))
+	}
+	tail := first
+	if m.Dir >= 3 {
+		tail = last
+	}
+	if m.Kind == KindSelfEject { // 领头子的落点在棋盘外，没有坐标
+		return fmt.Sprintf("%sx%d", g.CellName(tail), m.Dir)
+	}
+	return g.CellName(tail) + g.CellName(g.step(tail, m.Dir))
+}
+
+// ParseMove 解析标准记法并在当前局面下校验，返回可直接执行的 Move
+func (g *Game) ParseMove(s string) (Move, error) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, "xX"); i > 0 {
+		return g.parseSelfEject(s, i)
+	}
+	names := splitCells(s)
+	if len(names) != 2 && len(names) != 3 {
+		return Move{}, fmt.Errorf("board: bad move %q", s)
+	}
+	cells := make([]int8, 0, 3)
+	for _, name := range names {
+		p, err := g.ParseCell(name)
+		if err != nil {
+			return Move{}, err
+		}
+		cells = append(cells, p)
+	}
+	me := g.CurrentPlayer
+
+	// ---- 直线：尾子 + 落点 ----
+	if len(cells) == 2 {
+		tail, to := cells[0], cells[1]
+		dir := g.neighborDir(tail, to)
+		if dir < 0 {
+			return Move{}, fmt.Errorf("board: move %q: cells are not adjacent", s)
+		}
+		if g.TokenAt(tail) != me {
+			return Move{}, badMove(s, g.moveErr(ReasonNotOwnMarble, tail))
+		}
+		group, err := g.runFrom(tail, dir)
+		if err != nil {
+			return Move{}, badMove(s, err)
+		}
+		m, err := g.GroupMove(group, dir)
+		return m, badMove(s, err)
+	}
+
+	// ---- 侧移：首子 + 末子 + 首子落点 ----
+	first, last, to := cells[0], cells[1], cells[2]
+	if first > last {
+		first, last = last, first
+	}
+	axis := int8(-1)
+	var group []int8
+	for d := int8(0); d < 3 && axis < 0; d++ {
+		group = []int8{first}
+		for p := g.step(first, d); p >= 0 && len(group) < int(g.rules.MaxLine); p = g.step(p, d) {
+			group = append(group, p)
+			if p == last {
+				axis = d
+				break
+			}
+		}
+	}
+	if axis < 0 {
+		return Move{}, badMove(s, g.moveErr(ReasonNotInLine, last))
+	}
+	for _, p := range group {
+		if g.TokenAt(p) != me {
+			return Move{}, badMove(s, g.moveErr(ReasonNotOwnMarble, p))
+		}
+	}
+	dir := g.neighborDir(first, to)
+	if dir < 0 || dir%3 == axis {
+		return Move{}, badMove(s, g.moveErr(ReasonBadDirection, to))
+	}
+	m, err := g.GroupMove(group, dir)
+	return m, badMove(s, err)
+}
+
+// splitCells 把记法切成格子名：每格一个字母加其后的数字；格式不对返回 nil
+func splitCells(s string) []string {
+	var out []string
+	for i := 0; i < len(s); {
+		j := i + 1
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+		}
+		if j == i+1 {
+			return nil
+		}
+		out = append(out, s[i:j])
+		i = j
+	}
+	return out
+}
+
+// parseSelfEject 解析 "A1x3"：尾子 + 方向下标；x 位于 s[i]
+func (g *Game) parseSelfEject(s string, i int) (Move, error) {
+	tail, err := g.ParseCell(s[:i])
+	if err != nil {
+		return Move{}, err
+	}
+	if len(s) != i+2 {
+		return Move{}, fmt.Errorf("board: bad move %q", s)
+	}
+	dir := int8(s[i+1]) - '0'
+	if dir < 0 || dir >= 6 {
+		return Move{}, fmt.Errorf("board: move %q: bad direction", s)
+	}
+	if g.TokenAt(tail) != g.CurrentPlayer {
+		return Move{}, badMove(s, g.moveErr(ReasonNotOwnMarble, tail))
+	}
+	group, err := g.runFrom(tail, dir)
+	if err != nil {
+		return Move{}, badMove(s, err)
+	}
+	m, err := g.GroupMove(group, dir)
+	if err == nil && m.Kind != KindSelfEject {
+		err = g.moveErr(ReasonBadDirection, tail)
+	}
+	return m, badMove(s, err)
+}
+
+// runFrom 从尾子 tail 沿 dir 收集连续的己方棋子，超过 MaxLine 报错
+func (g *Game) runFrom(tail, dir int8) ([]int8, error) {
+	me := g.CurrentPlayer
+	group := []int8{tail}
+	for p := g.step(tail, dir); p >= 0 && g.TokenAt(p) == me; p = g.step(p, dir) {
+		if len(group) == int(g.rules.MaxLine) {
+			return nil, g.moveErr(ReasonGroupTooLarge, p)
+		}
+		group = append(group, p)
+	}
+	return group, nil
+}
+
+// badMove 给走法错误加上原始记法，保留 *MoveError 供 errors.As 使用
+func badMove(s string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("board: move %q: %w", s, err)
+}
+
+// Neighbor 返回 pos 沿 ACTIONS[dir] 的相邻格；出界为 -1
+func (g *Game) Neighbor(pos, dir int8) int8 { return g.step(pos, dir) }
+
+// step 返回 pos 沿 dir 前进一格的索引；出界为 -1
+func (g *Game) step(pos, dir int8) int8 {
+	r, c := g.PosToCoord(pos)
+	return g.CoordToPos(r+ACTIONS[dir][0], c+ACTIONS[dir][1])
+}
+
+// neighborDir 返回 to 相对 from 的方向；不相邻为 -1
+func (g *Game) neighborDir(from, to int8) int8 {
+	for d := int8(0); d < 6; d++ {
+		if g.step(from, d) == to {
+			return d
+		}
+	}
+	return -1
+}
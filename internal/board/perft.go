@@ -0,0 +1,66 @@
+// File internal/board/perft.go
+package board
+
+import "sort"
+
+// ---- Perft：数走法树的叶子，校验走法生成 ----
+
+// Perft 从 g 出发走满 depth 层的叶子数；已结束的局面不再展开（计 0）。
+// g 在返回时恢复原状。
+func Perft(g *Game, depth int) uint64 {
+	if depth <= 0 {
+		return 1
+	}
+	if g.Result.Over() {
+		return 0
+	}
+	moves := LegalMoves(g)
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+	var n uint64
+	for _, m := range moves {
+		u := g.Make(m)
+		n += Perft(g, depth-1)
+		g.Unmake(u)
+	}
+	return n
+}
+
+// DivideEntry Divide 中的一行：根节点的一步及其下方的叶子数
+type DivideEntry struct {
+	Move  string // 标准记法
+	Nodes uint64
+}
+
+// Divide 按根节点的每一步拆开 Perft(g, depth)，按记法排序；
+// 与另一实现对比时可以逐层定位出错的走法。
+func Divide(g *Game, depth int) []DivideEntry {
+	if depth <= 0 || g.Result.Over() {
+		return nil
+	}
+	moves := LegalMoves(g)
+	out := make([]DivideEntry, 0, len(moves))
+	for _, m := range moves {
+		name := g.FormatMove(m)
+		u := g.Make(m)
+		out = append(out, DivideEntry{name, Perft(g, depth-1)})
+		g.Unmake(u)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Move < out[j].Move })
+	return out
+}
+
+// PerftCase 一组参考值：开局（variants.json 中的名字，空为内置摆法）与 depth=1.. 的叶子数
+type PerftCase struct {
+	Variant string
+	Counts  []uint64
+}
+
+// PerftReference 标准规则、A 先走的参考值（depth=5 单线程约一分钟）。改动规则或走法生成后
+// 用 `abalone perft -check` 核对；数值变化必须能解释清楚再更新这里。
+var PerftReference = []PerftCase{
+	{Variant: "classical", Counts: []uint64{44, 1936, 98912, 5045110, 283320928}},
+	{Variant: "belgian-daisy", Counts: []uint64{52, 2692, 149322, 8270666, 483942012}},
+	{Variant: "", Counts: []uint64{46, 2116, 112148, 5943683, 343131737}},
+}
@@ -0,0 +1,55 @@
+// File internal/board/perft_test.go
+package board
+
+import "testing"
+
+const perftTestDepth = 3 // 更深的层数用 `abalone perft -check` 核对
+
+func perftGame(t *testing.T, variant string) *Game {
+	t.Helper()
+	if variant == "" {
+		return NewGame(PlayerA, StandardRules())
+	}
+	g, err := NewGameFromVariant(variant, PlayerA, StandardRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// TestPerft 与 PerftReference 逐层核对，走完后局面复原
+func TestPerft(t *testing.T) {
+	for _, c := range PerftReference {
+		g := perftGame(t, c.Variant)
+		before := g.String()
+		for depth := 1; depth <= perftTestDepth && depth <= len(c.Counts); depth++ {
+			if got, want := Perft(g, depth), c.Counts[depth-1]; got != want {
+				t.Errorf("perft %q depth %d = %d, want %d", c.Variant, depth, got, want)
+			}
+		}
+		if after := g.String(); after != before {
+			t.Errorf("perft %q changed the position: %s -> %s", c.Variant, before, after)
+		}
+	}
+}
+
+// TestDivide 各根走法的叶子数之和等于 Perft，且每步只出现一次
+func TestDivide(t *testing.T) {
+	for _, c := range PerftReference {
+		g := perftGame(t, c.Variant)
+		for depth := 1; depth <= perftTestDepth; depth++ {
+			var sum uint64
+			seen := map[string]bool{}
+			for _, e := range Divide(g, depth) {
+				if seen[e.Move] {
+					t.Fatalf("divide %q depth %d: %s listed twice", c.Variant, depth, e.Move)
+				}
+				seen[e.Move] = true
+				sum += e.Nodes
+			}
+			if want := Perft(g, depth); sum != want {
+				t.Errorf("divide %q depth %d sums to %d, perft is %d", c.Variant, depth, sum, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// File internal/board/players.go
+package board
+
+import "abalone_go/internal/zobrist"
+
+// 多人局（官方 3 人 / 4 人版，与两人局同一块棋盘）：
+//
+//	3 人：各 11 子，各自为战，先推出 MarblesToWin 颗对手棋子者胜
+//	4 人：各 9 子，A+C 对 B+D 两队，队内吃子数相加
+//
+// 按 A→B→C→D 轮流行棋；推子时前方可以是任意对手颜色的混合，队友的棋子与己子一样会挡路。
+
+// MaxPlayers 支持的最多人数
+const MaxPlayers = zobrist.MaxPlayers
+
+// Players 本局人数
+func (g *Game) Players() int8 { return g.rules.Players }
+
+// Team 返回玩家所属队伍：4 人局为 p%2（A+C / B+D），其余每人一队。
+// 队伍编号同时也是该队第一位玩家的下标。
+func (g *Game) Team(p int8) int8 {
+	if g.rules.Players == 4 {
+		return p % 2
+	}
+	return p
+}
+
+// IsOpponent token 是否为 player 的对手棋子（空格、VOID、己方与队友都不是）
+func (g *Game) IsOpponent(player, token int8) bool {
+	return token >= 0 && g.Team(token) != g.Team(player)
+}
+
+// Captures 返回玩家已推出的对手棋子数
+func (g *Game) Captures(player int8) int8 { return g.captures[player] }
+
+// TeamCaptures 返回玩家所在队伍的吃子总数；非团队局等于 Captures
+func (g *Game) TeamCaptures(player int8) int8 {
+	var n int8
+	for p := int8(0); p < g.rules.Players; p++ {
+		if g.Team(p) == g.Team(player) {
+			n += g.captures[p]
+		}
+	}
+	return n
+}
+
+// nextPlayer 下一位行棋者
+func (g *Game) nextPlayer(p int8) int8 { return (p + 1) % g.rules.Players }
+
+// passTurn 换手并同步哈希
+func (g *Game) passTurn() {
+	g.hash = zobrist.ToggleSide(g.hash, g.CurrentPlayer)
+	g.CurrentPlayer = g.nextPlayer(g.CurrentPlayer)
+	g.hash = zobrist.ToggleSide(g.hash, g.CurrentPlayer)
+}
+
+// opponents 当前玩家所有对手棋子的位图
+func (g *Game) opponents(player int8) bitboard {
+	var b bitboard
+	for p := int8(0); p < g.rules.Players; p++ {
+		if g.Team(p) != g.Team(player) {
+			b = b.or(g.pieces[p])
+		}
+	}
+	return b
+}
+
+// ---- 内置摆法 ----
+
+// defaultLayouts 按 [边长][人数] 索引的内置开局，包初始化时生成
+var defaultLayouts = func() (out [MaxSide + 1][MaxPlayers + 1][][]int8) {
+	for side := int8(StandardSide); side <= MaxSide; side++ {
+		out[side][2], out[side][3], out[side][4] = twoPlayerLayout(side), threePlayerLayout(side), fourPlayerLayout(side)
+	}
+	return
+}()
+
+// defaultLayout 返回内置开局：每方的初始格子
+func defaultLayout(side, players int8) [][]int8 { return defaultLayouts[side][players] }
+
+// twoPlayerLayout 各占两条边行，再加第三行靠左 / 靠右 side-2 格；边长 5 时各 14 子
+func twoPlayerLayout(s int8) [][]int8 {
+	return hexLayout(s,
+		func(x, y, z int8) bool { return z <= 2-s || (z == 3-s && x <= s-5) }, // A：上方
+		func(x, y, z int8) bool { return z >= s-2 || (z == s-3 && x >= 5-s) }, // B：下方
+	)
+}
+
+// threePlayerLayout 三条不相邻的边，各占边行 + 次行；边长 5 时各 11 子
+func threePlayerLayout(s int8) [][]int8 {
+	return hexLayout(s,
+		func(x, y, z int8) bool { return z <= 2-s }, // A：上边
+		func(x, y, z int8) bool { return y <= 2-s }, // B：右下边
+		func(x, y, z int8) bool { return x <= 2-s }, // C：左下边
+	)
+}
+
+// fourPlayerLayout 上 / 右上 / 下 / 左下四条边，相邻两边的角格按顺时针归属，队友隔边相对；
+// 边长 5 时各 9 子
+func fourPlayerLayout(s int8) [][]int8 {
+	return hexLayout(s,
+		func(x, y, z int8) bool { return z <= 2-s && x <= s-2 },               // A：上边
+		func(x, y, z int8) bool { return x == s-1 || (x == s-2 && z >= 3-s) }, // B：右上边
+		func(x, y, z int8) bool { return z >= s-2 && x >= 2-s },               // C：下边
+		func(x, y, z int8) bool { return x == 1-s || (x == 2-s && z <= s-3) }, // D：左下边
+	)
+}
+
+// hexLayout 用立方坐标（x=c-side, z=r-side, y=-x-z）描述每方的初始区域
+func hexLayout(side int8, areas ...func(x, y, z int8) bool) [][]int8 {
+	g := &Game{rules: RuleSet{BoardSide: side}}
+	g.initCoordTables()
+	layout := make([][]int8, len(areas))
+	for pos := int8(0); pos < g.cells; pos++ {
+		r, c := g.PosToCoord(pos)
+		x, z := c-side, r-side
+		for p, in := range areas {
+			if in(x, -x-z, z) {
+				layout[p] = append(layout[p], pos)
+				break
+			}
+		}
+	}
+	return layout
+}
@@ -0,0 +1,298 @@
+// File internal/board/position.go
+package board
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"abalone_go/internal/zobrist"
+)
+
+// 文本局面格式（类似国际象棋 FEN），空格分隔五段：
+//
+//	v1 aaaaa/aaaaaa/aaa4/8/9/8/4bbb/bbbbbb/bbbbb a 0-0 1
+//	│  │                                          │ │   └ 回合数 TurnCount
+//	│  │                                          │ └ 双方已被推出数 A-B
+//	│  │                                          └ 轮到谁走 a/b
+//	│  └ 9 行棋盘，自上而下与 posIndex 顺序一致；a/b 为棋子，数字为连续空格
+//	└ 格式版本
+//
+// 3/4 人局棋子另有 c/d，第四段写出每人的被推出数，再用冒号接上每人的吃子数，
+// 如 "0-1-0:1-0-0"；人数由被推出数的个数决定。两人局的吃子数通常就是对方的被推出数，不另写
+// （有让子 HeadStart 时再加上载入方对局的 HeadStart；文本本身不含摆法与让子）；
+// 走出己子判负（RuleSet.SelfEjectLoses）之后两者不再相等，这时也写出吃子数，如 "1-0:0-0"。
+// 被推出总数比吃子总数多出的一颗就是走出的己子，载入时据此恢复“走出己子判负”的结果。
+//
+// 大棋盘的行数为 2·side-1（边长 6 为 11 行，7 为 13 行），边长由行数决定；
+// 连续空格超过 9 个时写成多位数，如 "13"。
+const positionVersion = "v1"
+
+var playerChars = [MaxPlayers]byte{'a', 'b', 'c', 'd'}
+
+// rowLens 边长 side 的棋盘自上而下每行的格子数，如边长 5 为 5 6 7 8 9 8 7 6 5
+func rowLens(side int8) []int {
+	s := int(side)
+	out := make([]int, 2*s-1)
+	for i := range out {
+		out[i] = s + min(i, 2*s-2-i)
+	}
+	return out
+}
+
+// MarshalText 实现 encoding.TextMarshaler
+func (g *Game) MarshalText() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(positionVersion)
+	buf.WriteByte(' ')
+
+	pos := int8(0)
+	for row, n := range rowLens(g.side) {
+		if row > 0 {
+			buf.WriteByte('/')
+		}
+		empty := 0
+		for k := 0; k < n; k++ {
+			tok := g.TokenAt(pos)
+			pos++
+			if tok == TokenEmpty {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				buf.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			buf.WriteByte(playerChars[tok])
+		}
+		if empty > 0 {
+			buf.WriteString(strconv.Itoa(empty))
+		}
+	}
+
+	n := g.rules.Players
+	fmt.Fprintf(&buf, " %c %s", playerChars[g.CurrentPlayer], joinCounts(g.playerDamages[:n]))
+	if n > 2 || g.captures != g.derivedCaptures(g.playerDamages) {
+		fmt.Fprintf(&buf, ":%s", joinCounts(g.captures[:n]))
+	}
+	fmt.Fprintf(&buf, " %d", g.TurnCount)
+	return buf.Bytes(), nil
+}
+
+// String 返回文本局面，方便日志与调试
+func (g *Game) String() string {
+	b, _ := g.MarshalText()
+	return string(b)
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler；任何格式偏差都会报错且不修改 g
+func (g *Game) UnmarshalText(text []byte) error {
+	fields := strings.Split(string(text), " ")
+	if len(fields) != 5 {
+		return fmt.Errorf("board: position: want 5 fields, got %d", len(fields))
+	}
+	if fields[0] != positionVersion {
+		return fmt.Errorf("board: position: unsupported version %q", fields[0])
+	}
+
+	// ---- 棋盘：行数决定边长 ----
+	var cells [MaxN]int8
+	rows := strings.Split(fields[1], "/")
+	hexSide := int8((len(rows) + 1) / 2)
+	if want := g.rules.BoardSide; want != 0 && hexSide != want {
+		return fmt.Errorf("board: position: want %d rows, got %d", 2*want-1, len(rows))
+	}
+	if len(rows)%2 == 0 || hexSide < StandardSide || hexSide > MaxSide {
+		return fmt.Errorf("board: position: bad row count %d", len(rows))
+	}
+	lens := rowLens(hexSide)
+	pos := 0
+	for i, row := range rows {
+		if n := countRow(row); n != lens[i] {
+			return fmt.Errorf("board: position: row %d has %d cells, want %d", i+1, n, lens[i])
+		}
+		for k := 0; k < len(row); k++ {
+			ch := row[k]
+			switch {
+			case ch >= '1' && ch <= '9':
+				run := int(ch - '0')
+				for k+1 < len(row) && row[k+1] >= '0' && row[k+1] <= '9' {
+					k++
+					run = run*10 + int(row[k]-'0')
+				}
+				for e := 0; e < run; e++ {
+					cells[pos] = TokenEmpty
+					pos++
+				}
+				continue
+			case ch >= playerChars[0] && ch < playerChars[0]+MaxPlayers:
+				cells[pos] = int8(ch - playerChars[0])
+			default:
+				return fmt.Errorf("board: position: row %d: bad character %q", i+1, ch)
+			}
+			pos++
+		}
+	}
+
+	// ---- 被推出数 / 吃子数，同时确定人数 ----
+	dmgField, capField, multi := strings.Cut(fields[3], ":")
+	damages, n, err := parseCounts(dmgField)
+	if err != nil || n < 2 || n > MaxPlayers || (n > 2 && !multi) {
+		return fmt.Errorf("board: position: bad ejection field %q", fields[3])
+	}
+	rules := g.rulesOrStandard()
+	if g.rules.Players == 0 {
+		rules.Players = n // 零值 Game：按文本里的人数与边长取标准规则
+		rules.BoardSide = hexSide
+	} else if rules.Players != n {
+		return fmt.Errorf("board: position: %d players, game has %d", n, rules.Players)
+	}
+	var captures [MaxPlayers]int8
+	if multi {
+		var nc int8
+		if captures, nc, err = parseCounts(capField); err != nil || nc != n {
+			return fmt.Errorf("board: position: bad capture field %q", capField)
+		}
+	} else {
+		captures = g.derivedCaptures(damages)
+	}
+	for p := int8(0); p < n; p++ {
+		if captures[p] > rules.MarblesToWin || (n == 2 && damages[p] > rules.MarblesToWin) {
+			return fmt.Errorf("board: position: bad ejection field %q", fields[3])
+		}
+	}
+	for _, tok := range cells[:pos] {
+		if tok >= n {
+			return fmt.Errorf("board: position: piece %c in a %d-player game", playerChars[tok], n)
+		}
+	}
+
+	// ---- 行棋方 ----
+	side := int8(-1)
+	if len(fields[2]) == 1 {
+		side = int8(fields[2][0]) - int8(playerChars[0])
+	}
+	if side < 0 || side >= n {
+		return fmt.Errorf("board: position: bad side to move %q", fields[2])
+	}
+
+	// ---- 回合数 ----
+	turn, err := strconv.Atoi(fields[4])
+	if err != nil || turn < 1 || strconv.Itoa(turn) != fields[4] {
+		return fmt.Errorf("board: position: bad turn number %q", fields[4])
+	}
+
+	// ---- 格式通过：在副本上落子，整体校验后再写回 ----
+	t := *g
+	if t.layout == nil {
+		t.layout = defaultLayout(hexSide, n)
+	}
+	t.rules = rules
+	t.initCoordTables()
+	for p := int8(0); p < t.cells; p++ {
+		r, c := t.PosToCoord(p)
+		t.Cells[r][c] = cells[p]
+	}
+	t.syncBitboards()
+	t.playerDamages = damages
+	t.captures = captures
+	t.CurrentPlayer = side
+	t.TurnCount = turn
+	t.Result = Result{}
+	for p := int8(0); p < n; p++ {
+		if t.TeamCaptures(p) >= t.rules.MarblesToWin {
+			t.Result = t.winFor(p, EndEjection)
+		}
+		if t.selfEjected(p) {
+			t.Result = t.winFor(t.nextPlayer(p), EndSelfEjection)
+		}
+	}
+	t.history = nil
+	t.rehash()
+	t.positions = []uint64{t.hash}
+	t.variant = ""
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	*g = t
+	return nil
+}
+
+// derivedCaptures 两人局由被推出数推出的吃子数：对方的被推出数加上自己的让子 HeadStart
+func (g *Game) derivedCaptures(damages [MaxPlayers]int8) [MaxPlayers]int8 {
+	head := g.handicap.HeadStart
+	var captures [MaxPlayers]int8
+	captures[PlayerA], captures[PlayerB] = damages[PlayerB]+head[PlayerA], damages[PlayerA]+head[PlayerB]
+	return captures
+}
+
+// selfEjected p 所在队伍被推出的子比对手吃掉的多：多出的那颗是自己走出棋盘的
+func (g *Game) selfEjected(p int8) bool {
+	var lost, taken int
+	for q := int8(0); q < g.rules.Players; q++ {
+		if g.Team(q) == g.Team(p) {
+			lost += int(g.playerDamages[q])
+		} else {
+			taken += int(g.captures[q] - g.handicap.HeadStart[q])
+		}
+	}
+	return lost > taken
+}
+
+// joinCounts 输出 "0-1-0"
+func joinCounts(counts []int8) string {
+	parts := make([]string, len(counts))
+	for i, v := range counts {
+		parts[i] = strconv.Itoa(int(v))
+	}
+	return strings.Join(parts, "-")
+}
+
+// parseCounts 解析 "0-1-0"，返回各项与个数
+func parseCounts(s string) ([MaxPlayers]int8, int8, error) {
+	var out [MaxPlayers]int8
+	parts := strings.Split(s, "-")
+	if len(parts) > MaxPlayers {
+		return out, 0, fmt.Errorf("too many entries")
+	}
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 0 || v > zobrist.MaxDamage || strconv.Itoa(v) != part {
+			return out, 0, fmt.Errorf("bad count %q", part)
+		}
+		out[i] = int8(v)
+	}
+	return out, int8(len(parts)), nil
+}
+
+// countRow 统计一行文本代表的格子数；多位数按十进制读，不允许以 0 开头
+func countRow(row string) int {
+	n := 0
+	for k := 0; k < len(row); k++ {
+		ch := row[k]
+		if ch < '0' || ch > '9' {
+			n++
+			continue
+		}
+		if ch == '0' {
+			return -1
+		}
+		run := int(ch - '0')
+		for k+1 < len(row) && row[k+1] >= '0' && row[k+1] <= '9' {
+			k++
+			run = run*10 + int(row[k]-'0')
+		}
+		n += run
+	}
+	return n
+}
+
+// ParsePosition 按标准规则由文本局面创建对局；其他规则先 NewGame 再 UnmarshalText
+func ParsePosition(s string) (*Game, error) {
+	g := &Game{}
+	if err := g.UnmarshalText([]byte(s)); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
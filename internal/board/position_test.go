@@ -0,0 +1,75 @@
+// File internal/board/position_test.go
+package board
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// roundTrip 写出文本局面再读回，局面、吃子数、结果与哈希都应不变
+func roundTrip(t *testing.T, g *Game) {
+	t.Helper()
+	text := g.String()
+	c := NewGame(PlayerA, g.Rules())
+	if err := c.UnmarshalText([]byte(text)); err != nil {
+		t.Fatalf("%s: %v", text, err)
+	}
+	if got := c.String(); got != text {
+		t.Fatalf("%s: reads back as %s", text, got)
+	}
+	for p := int8(0); p < g.Players(); p++ {
+		if c.Captures(p) != g.Captures(p) || c.Damages(p) != g.Damages(p) {
+			t.Fatalf("%s: %c has %d captures / %d ejected after reload, want %d / %d",
+				text, 'A'+p, c.Captures(p), c.Damages(p), g.Captures(p), g.Damages(p))
+		}
+	}
+	if c.Result != g.Result {
+		t.Fatalf("%s: result %s after reload, want %s", text, c.Result, g.Result)
+	}
+	if c.Hash() != g.Hash() {
+		t.Fatalf("%s: hash %#x after reload, want %#x", text, c.Hash(), g.Hash())
+	}
+}
+
+// TestPositionRoundTrip 随机对局途经的局面与终局（含走出己子判负）都能原样读回
+func TestPositionRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, s := range testRuleSets {
+		rules, err := ParseRules(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 3; i++ {
+			g := NewGame(PlayerA, rules)
+			for ply := 0; ply < 150 && !g.Result.Over(); ply++ {
+				roundTrip(t, g)
+				moves := LegalMoves(g)
+				g.Play(moves[rng.Intn(len(moves))])
+			}
+			roundTrip(t, g)
+		}
+	}
+}
+
+// TestPositionSelfEject 走出己子判负后读回仍是判负，吃子数不变
+func TestPositionSelfEject(t *testing.T) {
+	for _, s := range []string{"selfeject=on", "players=4 win=4 selfeject=on"} {
+		rules, err := ParseRules(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, start := range []int8{PlayerA, PlayerB} {
+			g := NewGame(start, rules)
+			for _, m := range LegalMoves(g) {
+				if m.Kind == KindSelfEject {
+					g.Play(m)
+					break
+				}
+			}
+			if g.Result.Reason != EndSelfEjection {
+				t.Fatalf("%s: no self-eject move from the start position", s)
+			}
+			roundTrip(t, g)
+		}
+	}
+}
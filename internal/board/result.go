@@ -0,0 +1,149 @@
+// File internal/board/result.go
+package board
+
+import "fmt"
+
+// Outcome 对局结果
+type Outcome uint8
+
+const (
+	Ongoing Outcome = iota
+	WinA
+	WinB
+	Draw
+	WinC // 仅 3 人局：4 人局按队伍记为 WinA / WinB
+	WinD
+)
+
+// EndReason 对局结束的原因
+type EndReason uint8
+
+const (
+	EndNone         EndReason = iota
+	EndEjection               // 推出 6 子
+	EndRepetition             // 同一局面第三次出现
+	EndMoveLimit              // 达到总步数上限
+	EndSelfEjection           // 把己方棋子走出棋盘（RuleSet.SelfEjectLoses）
+)
+
+var endReasonText = [...]string{
+	EndNone:         "",
+	EndEjection:     "ejection",
+	EndRepetition:   "threefold repetition",
+	EndMoveLimit:    "move limit",
+	EndSelfEjection: "self ejection",
+}
+
+// Result 对局状态；零值表示进行中
+type Result struct {
+	Outcome Outcome
+	Reason  EndReason
+}
+
+// Over 对局是否已结束（胜负或和棋）
+func (r Result) Over() bool { return r.Outcome != Ongoing }
+
+var winOutcomes = [MaxPlayers]Outcome{WinA, WinB, WinC, WinD}
+
+// Winner 返回胜方；团队局为胜队的第一位玩家（A 或 B），队友同胜。
+// 进行中或和棋返回 TokenEmpty
+func (r Result) Winner() int8 {
+	for p, o := range winOutcomes {
+		if r.Outcome == o {
+			return int8(p)
+		}
+	}
+	return TokenEmpty
+}
+
+func (r Result) String() string {
+	switch w := r.Winner(); {
+	case r.Outcome == Ongoing:
+		return "ongoing"
+	case w != TokenEmpty:
+		return fmt.Sprintf("%c wins (%s)", 'A'+w, endReasonText[r.Reason])
+	}
+	return fmt.Sprintf("draw (%s)", endReasonText[r.Reason])
+}
+
+// winFor 以玩家所在队伍的名义记胜
+func (g *Game) winFor(player int8, reason EndReason) Result {
+	return Result{winOutcomes[g.Team(player)], reason}
+}
+
+// declare 写入结果，胜队每位玩家的胜局数加一
+func (g *Game) declare(r Result) {
+	g.Result = r
+	if w := r.Winner(); w != TokenEmpty {
+		for p := int8(0); p < g.rules.Players; p++ {
+			if g.Team(p) == w {
+				g.PlayerVictories[p]++
+			}
+		}
+	}
+}
+
+// ---- 步数上限 ----
+
+// MoveLimit 总步数上限；Moves 为 0 表示不限。
+// 到达上限时 ByMarbles 为 true 按吃子数判胜负（团队局按队伍合计，相同则和），否则直接判和。
+type MoveLimit struct {
+	Moves     int
+	ByMarbles bool
+}
+
+// TournamentLimit 常见比赛规则：200 步，按子数判定；填入 RuleSet.MoveLimit
+var TournamentLimit = MoveLimit{Moves: 200, ByMarbles: true}
+
+// ---- 重复局面 ----
+
+// Repetitions 当前局面（含行棋方与被推出数）在本局中出现的次数
+func (g *Game) Repetitions() int {
+	n := 0
+	// 两人局每步都换行棋方，只有同奇偶的局面才可能相同；让一手之后当前局面不在表里，错开一格。
+	// 多人局哈希里已含行棋方，逐个比较即可
+	i, step := len(g.positions)-1, 1
+	if g.rules.Players == 2 {
+		step = 2
+		if g.positions[i] != g.hash {
+			i--
+		}
+	}
+	for ; i >= 0; i -= step {
+		if g.positions[i] == g.hash {
+			n++
+		}
+	}
+	return n
+}
+
+// adjudicate 在 Apply 之后判定和棋 / 步数上限；推子胜负已在 Apply 里处理
+func (g *Game) adjudicate() {
+	if g.Result.Over() {
+		return
+	}
+	if g.Repetitions() >= 3 {
+		g.Result = Result{Draw, EndRepetition}
+		return
+	}
+	if l := g.rules.MoveLimit; l.Moves > 0 && g.TurnCount-1 >= l.Moves {
+		// 按子数判定：吃子最多的队伍获胜，并列最多则和
+		best, leader := int8(-1), TokenEmpty
+		for p := int8(0); p < g.rules.Players; p++ {
+			if g.Team(p) != p {
+				continue // 每队只看一次
+			}
+			switch n := g.TeamCaptures(p); {
+			case n > best:
+				best, leader = n, p
+			case n == best:
+				leader = TokenEmpty
+			}
+		}
+		if !l.ByMarbles || leader == TokenEmpty {
+			g.declare(Result{Draw, EndMoveLimit})
+			return
+		}
+		g.declare(g.winFor(leader, EndMoveLimit))
+	}
+}
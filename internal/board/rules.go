@@ -1,7 +1,12 @@
 // File internal/board/rules.go
 package board
 
-import "math"
+import (
+	"math"
+	"sort"
+
+	"abalone_go/internal/zobrist"
+)
 
 // ---------------- 内部辅助 -----------------
 
@@ -43,46 +48,72 @@ func tern(cond bool, a, b int8) int8 {
 
 // --------------- Move 验证主入口 ----------------
 
-// ValidateMove 与 Python 的返回语义保持一致：
-// ok=false 不合法；否则返回 moveType 和 modifications slice。
+// Modification 一颗棋子的位移记录
 type Modification struct {
 	OldPos, NewPos int8 // NewPos=-1 表示被推出棋盘
 	DirIndex       int8 // 0-5 方向；-1 代表 eject
 	Piece          int8 // board.PlayerA 或 PlayerB
 }
 
-func (g *Game) ValidateMove(pos0, pos1 int8) (ok bool, moveType string, mods []Modification) {
+// ValidateMove 按 Python 版的两点输入判定走法：
+// 合法时返回走法类型和 modifications slice；非法时返回 *MoveError 说明原因。
+// 两点输入靠启发式推断走法类型，部分侧移无法表达；新代码请用 GroupMove。
+func (g *Game) ValidateMove(pos0, pos1 int8) (MoveKind, []Modification, error) {
+	if g.Result.Over() {
+		return 0, nil, g.moveErr(ReasonGameOver, -1)
+	}
+	if pos0 < 0 || pos0 >= g.cells {
+		return 0, nil, g.moveErr(ReasonNotOwnMarble, -1)
+	}
+	if pos1 < 0 || pos1 >= g.cells {
+		return 0, nil, g.moveErr(ReasonOffBoard, pos0)
+	}
 	player := g.CurrentPlayer
 	r0, c0 := g.PosToCoord(pos0)
 	r1, c1 := g.PosToCoord(pos1)
-	if g.Cells[r0][c0] != player || g.Cells[r1][c1] == player {
-		return
+	if g.Cells[r0][c0] != player {
+		return 0, nil, g.moveErr(ReasonNotOwnMarble, pos0)
+	}
+	if tok := g.Cells[r1][c1]; tok >= 0 && !g.IsOpponent(player, tok) { // 己子或队友
+		return 0, nil, g.moveErr(ReasonDestinationNotFree, pos1)
 	}
 
+	var rj rejection
 	switch g.Cells[r1][c1] {
 	case TokenEmpty:
-		if mt, m := g.inlineMove(r0, c0, r1, c1); mt != "" {
-			return true, mt, m
+		mt, m, inlineRj := g.inlineMove(r0, c0, r1, c1)
+		if inlineRj.ok() {
+			return mt, m, nil
 		}
-		if mt, m := g.sideStepMove(r0, c0, r1, c1); mt != "" {
-			return true, mt, m
+		mt, m, rj = g.sideStepMove(r0, c0, r1, c1)
+		if rj.ok() {
+			return mt, m, nil
+		}
+		// 两点本就在一条直线上时，直线走法的原因更贴切
+		if _, _, inline := inlineDecompose(r1-r0, c1-c0); inline {
+			rj = inlineRj
 		}
 	default: // 敌子
-		if mt, m := g.inlinePush(r0, c0, r1, c1); mt != "" {
-			return true, mt, m
+		var mt MoveKind
+		var m []Modification
+		if mt, m, rj = g.inlinePush(r0, c0, r1, c1); rj.ok() {
+			return mt, m, nil
 		}
 	}
-	return
+	return 0, nil, g.rejectErr(rj)
 }
 
 // ------------- 三种走法检查 ------------------
 
-func (g *Game) inlineMove(r0, c0, r1, c1 int8) (string, []Modification) {
+func (g *Game) inlineMove(r0, c0, r1, c1 int8) (MoveKind, []Modification, rejection) {
 	// Δ 坐标
 	dr, dc := r1-r0, c1-c0
 	step, dir, ok := inlineDecompose(dr, dc)
-	if !ok || step == 0 || step > 3 {
-		return "", nil
+	if !ok || step == 0 {
+		return 0, nil, rejection{ReasonBadDirection, g.CoordToPos(r1, c1)}
+	}
+	if step > g.rules.MaxLine {
+		return 0, nil, rejection{ReasonGroupTooLarge, g.CoordToPos(r0, c0)}
 	}
 	rStep, cStep := ACTIONS[dir][0], ACTIONS[dir][1]
 
@@ -90,7 +121,7 @@ func (g *Game) inlineMove(r0, c0, r1, c1 int8) (string, []Modification) {
 	for n := int8(0); n < step; n++ {
 		rr, cc := r0+n*rStep, c0+n*cStep
 		if g.Cells[rr][cc] != g.CurrentPlayer {
-			return "", nil
+			return 0, nil, rejection{ReasonNotInLine, g.CoordToPos(rr, cc)}
 		}
 	}
 
@@ -114,30 +145,44 @@ func (g *Game) inlineMove(r0, c0, r1, c1 int8) (string, []Modification) {
 			DirIndex: dir,
 		}
 	}
-	return "inline_move", mods
+	return KindInlineMove, mods, rejection{}
 }
 
-// inlinePush 实现了 Python 版的 “Sumito” 规则：2vs1、3vs1、3vs2 推子逻辑。
-func (g *Game) inlinePush(r0, c0, r1, c1 int8) (string, []Modification) {
+// inlinePush 实现了 Python 版的 “Sumito” 规则，允许的人数比见 RuleSet.Pushes。
+func (g *Game) inlinePush(r0, c0, r1, c1 int8) (MoveKind, []Modification, rejection) {
 	dr, dc := r1-r0, c1-c0
 	step, dir, ok := inlineDecompose(dr, dc)
-	if !ok || step > 4 {
-		return "", nil
+	if !ok {
+		return 0, nil, rejection{ReasonBadDirection, g.CoordToPos(r1, c1)}
+	}
+	if step > 2*g.rules.MaxLine-1 { // 最长：MaxLine 颗推 MaxLine-1 颗
+		return 0, nil, rejection{ReasonGroupTooLarge, g.CoordToPos(r0, c0)}
 	}
 	rStep, cStep := ACTIONS[dir][0], ACTIONS[dir][1]
 
 	//---------------- 1) 收集连续棋串 ------------------
+	// 按“己子 / 对手 / 队友”分段；多人局里不同颜色的对手算同一段
+	me := g.CurrentPlayer
+	class := func(tok int8) int8 {
+		switch {
+		case tok == me:
+			return 0
+		case g.IsOpponent(me, tok):
+			return 1
+		}
+		return 2
+	}
 	tail := [][]int8{{r0, c0}} // tail[0] 我方，tail[1] 敌方
-	prev := g.CurrentPlayer
+	prev := class(me)
 	reached := false
 	rr, cc := r0+rStep, c0+cStep
 	for {
 		if g.Cells[rr][cc] == TokenEmpty || g.Cells[rr][cc] == TokenVoid {
 			break
 		}
-		if g.Cells[rr][cc] != prev {
+		if k := class(g.Cells[rr][cc]); k != prev {
 			tail = append(tail, []int8{rr, cc})
-			prev = g.Cells[rr][cc]
+			prev = k
 		} else {
 			last := len(tail) - 1
 			tail[last] = append(tail[last], rr, cc)
@@ -150,18 +195,24 @@ func (g *Game) inlinePush(r0, c0, r1, c1 int8) (string, []Modification) {
 	}
 
 	//---------------- 2) 合规校验 -----------------------
-	if !reached || len(tail) != 2 {
-		return "", nil
+	if !reached {
+		return 0, nil, rejection{ReasonNotInLine, g.CoordToPos(r1, c1)}
+	}
+	if len(tail) != 2 { // 敌子后面又是己子或队友
+		return 0, nil, rejection{ReasonBlockedByOwn, g.CoordToPos(tail[2][0], tail[2][1])}
 	}
 	nFriends, nEnemies := len(tail[0])/2, len(tail[1])/2
-	if nFriends <= nEnemies || nFriends > 3 {
-		return "", nil
+	if nFriends > int(g.rules.MaxLine) {
+		return 0, nil, rejection{ReasonGroupTooLarge, g.CoordToPos(r0, c0)}
+	}
+	if !g.rules.canPush(int8(nFriends), int8(nEnemies)) {
+		return 0, nil, rejection{ReasonNotOutnumbered, g.CoordToPos(r1, c1)}
 	}
 
 	//---------------- 3) 处理顶出棋 ---------------------
 	mods := []Modification{}
 	destR, destC := rr, cc // 末尾的空格 / VOID
-	moveType := "inline_push"
+	moveType := KindInlinePush
 	if g.Cells[rr][cc] == TokenVoid { // 有顶出
 		outR, outC := rr-rStep, cc-cStep // 最后那颗敌棋
 		outPos := g.CoordToPos(outR, outC)
@@ -169,11 +220,10 @@ func (g *Game) inlinePush(r0, c0, r1, c1 int8) (string, []Modification) {
 			OldPos: outPos, NewPos: -1, DirIndex: -1,
 		})
 		// 更新胜负标记
-		damagedPlayer := g.Cells[outR][outC]
-		if g.playerDamages[damagedPlayer]+1 == lifes {
-			moveType = "winner"
+		if g.TeamCaptures(me)+1 == g.rules.MarblesToWin {
+			moveType = KindWinner
 		} else {
-			moveType = "ejected"
+			moveType = KindEjected
 		}
 		destR, destC = outR, outC // 链式移动的“空格”换成刚空出来的位置
 		// 把被顶出的坐标从“敌方串”里剪掉
@@ -205,7 +255,7 @@ func (g *Game) inlinePush(r0, c0, r1, c1 int8) (string, []Modification) {
 		})
 	}
 
-	return moveType, mods
+	return moveType, mods, rejection{}
 }
 
 // ----------------- 盘面修改 ---------------------
@@ -218,20 +268,55 @@ func (g *Game) Apply(mods []Modification) {
 		if m.NewPos == -1 { // eject
 			r, c := g.PosToCoord(m.OldPos)
 			damaged := g.Cells[r][c]
+			g.hash = zobrist.Toggle(g.hash, damaged, m.OldPos)
+			g.hash = zobrist.ToggleDamage(g.hash, damaged, g.playerDamages[damaged])
 			g.playerDamages[damaged]++
+			g.hash = zobrist.ToggleDamage(g.hash, damaged, g.playerDamages[damaged])
 			g.Cells[r][c] = TokenEmpty
-			if g.playerDamages[damaged] == lifes {
-				g.GameOver = true
-				g.PlayerVictories[g.CurrentPlayer]++
+			g.pieces[damaged].clear(g.gridBit(r, c))
+			if damaged == g.CurrentPlayer { // 只有 SelfEjectLoses 下才会走出己子，下家（对方队伍）获胜
+				g.declare(g.winFor(g.nextPlayer(damaged), EndSelfEjection))
+				continue
+			}
+			me := g.CurrentPlayer
+			g.hash = zobrist.ToggleCapture(g.hash, me, g.captures[me])
+			g.captures[me]++
+			g.hash = zobrist.ToggleCapture(g.hash, me, g.captures[me])
+			if g.TeamCaptures(me) == g.rules.MarblesToWin {
+				g.declare(g.winFor(me, EndEjection))
 			}
 			continue
 		}
 		rOld, cOld := g.PosToCoord(m.OldPos)
 		rNew, cNew := g.PosToCoord(m.NewPos)
+		piece := g.Cells[rOld][cOld]
+		g.hash = zobrist.Toggle(g.hash, piece, m.OldPos)
+		g.hash = zobrist.Toggle(g.hash, piece, m.NewPos)
+		g.movePiece(piece, m.OldPos, m.NewPos)
 		g.Cells[rNew][cNew], g.Cells[rOld][cOld] = g.Cells[rOld][cOld], TokenEmpty
 	}
-	g.CurrentPlayer ^= 1
+	g.passTurn()
 	g.TurnCount++
+	g.positions = append(g.positions, g.hash)
+	g.adjudicate()
+	g.debugValidate()
+}
+
+// MoveFromPair 把 (pos0,pos1) 两点输入转换为 Move，判定规则与 ValidateMove 相同
+func (g *Game) MoveFromPair(pos0, pos1 int8) (Move, error) {
+	mt, mods, err := g.ValidateMove(pos0, pos1)
+	if err != nil {
+		return Move{}, err
+	}
+	m := Move{Kind: mt, Mods: mods}
+	for _, md := range mods {
+		if md.NewPos >= 0 && g.TokenAt(md.OldPos) == g.CurrentPlayer {
+			m.Group = append(m.Group, md.OldPos)
+			m.Dir = md.DirIndex
+		}
+	}
+	sort.Slice(m.Group, func(i, j int) bool { return m.Group[i] < m.Group[j] })
+	return m, nil
 }
 
 // 帮助把 [][2]int8 坐标切片转成 pos 切片
@@ -244,8 +329,8 @@ func (g *Game) CoordToPosSlice(coords [][2]int8) []int8 {
 }
 
 // sideStepMove 对应 Python 版的 check_sidestep_move，检查并返回“侧移”走法。
-// 如果合法，返回 moveType="sidestep_move" 和 modifications 列表；否则返回 "", nil。
-func (g *Game) sideStepMove(r0, c0, r1, c1 int8) (string, []Modification) {
+// 如果合法，返回 KindSidestepMove 和 modifications 列表；否则返回最后一次失败的原因。
+func (g *Game) sideStepMove(r0, c0, r1, c1 int8) (MoveKind, []Modification, rejection) {
 	// dr,dc 为目标相对起点的偏移
 	dr, dc := r1-r0, c1-c0
 
@@ -269,6 +354,7 @@ func (g *Game) sideStepMove(r0, c0, r1, c1 int8) (string, []Modification) {
 	//    并根据正负方向选取对应的两个候选 side_move 指向
 	actP := [3][2]int8{{1, 2}, {0, 5}, {0, 1}}
 	actN := [3][2]int8{{4, 5}, {3, 2}, {3, 4}}
+	fail := rejection{ReasonBadDirection, g.CoordToPos(r1, c1)}
 
 	for i := 0; i < 3; i++ {
 		if absInt8(decomp[i]) != 1 {
@@ -294,8 +380,13 @@ func (g *Game) sideStepMove(r0, c0, r1, c1 int8) (string, []Modification) {
 		inlineStep, inlineMove := tmp[sideMove][0], tmp[sideMove][1]
 		drInline, dcInline := ACTIONS[inlineMove][0], ACTIONS[inlineMove][1]
 
-		// 5. 限制：inlineStep < 3
-		if inlineStep >= 3 {
+		// 5. 限制：棋串不超过 MaxLine；关闭侧移时只剩单子
+		if inlineStep >= g.rules.MaxLine {
+			fail = rejection{ReasonGroupTooLarge, g.CoordToPos(r0, c0)}
+			continue
+		}
+		if inlineStep > 0 && !g.rules.Broadside {
+			fail = rejection{ReasonNotAllowed, g.CoordToPos(r0, c0)}
 			continue
 		}
 
@@ -316,6 +407,7 @@ func (g *Game) sideStepMove(r0, c0, r1, c1 int8) (string, []Modification) {
 		for _, rc := range oldCoords {
 			if g.Cells[rc[0]][rc[1]] != g.CurrentPlayer {
 				connected = false
+				fail = rejection{ReasonNotInLine, g.CoordToPos(rc[0], rc[1])}
 				break
 			}
 		}
@@ -328,6 +420,10 @@ func (g *Game) sideStepMove(r0, c0, r1, c1 int8) (string, []Modification) {
 		for _, rc := range newCoords {
 			if g.Cells[rc[0]][rc[1]] != TokenEmpty {
 				free = false
+				fail = rejection{ReasonDestinationNotFree, g.CoordToPos(rc[0], rc[1])}
+				if g.Cells[rc[0]][rc[1]] == TokenVoid {
+					fail = rejection{ReasonOffBoard, g.CoordToPos(r0, c0)}
+				}
 				break
 			}
 		}
@@ -352,10 +448,10 @@ func (g *Game) sideStepMove(r0, c0, r1, c1 int8) (string, []Modification) {
 		}
 
 		// 10. 返回合法的 sidestep_move
-		return "sidestep_move", mods
+		return KindSidestepMove, mods, rejection{}
 	}
 
-	return "", nil
+	return 0, nil, fail
 }
 
 // decomposeDirections 对应 Python 版的 decompose_directions(r, c)
@@ -0,0 +1,208 @@
+// File internal/board/ruleset.go
+package board
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"abalone_go/internal/zobrist"
+)
+
+// PushRatio 一种允许的推子人数比：Attackers 颗推 Defenders 颗
+type PushRatio struct {
+	Attackers, Defenders int8
+}
+
+// RuleSet 可调整的规则，随 NewGame 传入，开局后不再变化
+type RuleSet struct {
+	BoardSide      int8        // 六边形棋盘边长 5-7（61 / 91 / 127 格）
+	Players        int8        // 2-4 人；4 人局为 A+C 对 B+D 两队
+	MarblesToWin   int8        // 推出对方几颗获胜（团队局按队伍合计）
+	MaxLine        int8        // 一次最多移动几颗（直线与侧移）
+	Broadside      bool        // 是否允许侧移
+	Pushes         []PushRatio // 允许的推子比例
+	SelfEjectLoses bool        // 允许把己方棋子直线走出棋盘，走的一方立即判负
+	MoveLimit      MoveLimit   // 总步数上限
+}
+
+// maxLine 的上限：标准棋盘最短的边行只有 5 格
+const maxLineLimit = 5
+
+// StandardRules 标准规则：边长 5 的棋盘，两人，推出 6 子获胜，最多 3 颗，可侧移，2v1 / 3v1 / 3v2
+func StandardRules() RuleSet {
+	return RuleSet{
+		BoardSide:    StandardSide,
+		Players:      2,
+		MarblesToWin: 6,
+		MaxLine:      3,
+		Broadside:    true,
+		Pushes:       []PushRatio{{2, 1}, {3, 1}, {3, 2}},
+	}
+}
+
+// Validate 检查规则是否自洽
+func (rs RuleSet) Validate() error {
+	if rs.BoardSide < StandardSide || rs.BoardSide > MaxSide {
+		return fmt.Errorf("board: rules: board side must be %d..%d, got %d", StandardSide, MaxSide, rs.BoardSide)
+	}
+	if rs.Players < 2 || rs.Players > MaxPlayers {
+		return fmt.Errorf("board: rules: players must be 2..%d, got %d", MaxPlayers, rs.Players)
+	}
+	if rs.SelfEjectLoses && rs.Players == 3 {
+		return fmt.Errorf("board: rules: selfeject needs two sides, not 3 players")
+	}
+	if rs.MarblesToWin < 1 || int(rs.MarblesToWin) > zobrist.MaxDamage {
+		return fmt.Errorf("board: rules: marbles to win must be 1..%d, got %d", zobrist.MaxDamage, rs.MarblesToWin)
+	}
+	if rs.MaxLine < 1 || rs.MaxLine > maxLineLimit {
+		return fmt.Errorf("board: rules: max line must be 1..%d, got %d", maxLineLimit, rs.MaxLine)
+	}
+	for _, p := range rs.Pushes {
+		if p.Defenders < 1 || p.Attackers <= p.Defenders || p.Attackers > rs.MaxLine {
+			return fmt.Errorf("board: rules: bad push ratio %d:%d", p.Attackers, p.Defenders)
+		}
+	}
+	if rs.MoveLimit.Moves < 0 {
+		return fmt.Errorf("board: rules: negative move limit %d", rs.MoveLimit.Moves)
+	}
+	return nil
+}
+
+// canPush attackers 颗能否推 defenders 颗
+func (rs *RuleSet) canPush(attackers, defenders int8) bool {
+	for _, p := range rs.Pushes {
+		if p.Attackers == attackers && p.Defenders == defenders {
+			return true
+		}
+	}
+	return false
+}
+
+// Equal 两套规则是否完全相同
+func (rs RuleSet) Equal(o RuleSet) bool { return rs.String() == o.String() }
+
+// String 输出可被 ParseRules 读回的文本，如
+//
+//	side=5 players=2 win=6 line=3 broadside=on push=2:1,3:1,3:2 selfeject=off limit=200
+func (rs RuleSet) String() string {
+	push := make([]string, len(rs.Pushes))
+	for i, p := range rs.Pushes {
+		push[i] = fmt.Sprintf("%d:%d", p.Attackers, p.Defenders)
+	}
+	limit := strconv.Itoa(rs.MoveLimit.Moves)
+	if rs.MoveLimit.Moves > 0 && !rs.MoveLimit.ByMarbles {
+		limit += "/draw"
+	}
+	return fmt.Sprintf("side=%d players=%d win=%d line=%d broadside=%s push=%s selfeject=%s limit=%s",
+		rs.BoardSide, rs.Players, rs.MarblesToWin, rs.MaxLine, onOff(rs.Broadside), strings.Join(push, ","),
+		onOff(rs.SelfEjectLoses), limit)
+}
+
+// ParseRules 解析 String 的输出；未出现的项取标准规则，
+// 因此 "win=3 broadside=off" 这样的片段也可以。
+func ParseRules(s string) (RuleSet, error) {
+	rs := StandardRules()
+	for _, field := range strings.Fields(s) {
+		key, val, ok := strings.Cut(field, "=")
+		if !ok {
+			return RuleSet{}, fmt.Errorf("board: rules: bad field %q", field)
+		}
+		var err error
+		switch key {
+		case "side":
+			rs.BoardSide, err = parseInt8(val)
+		case "players":
+			rs.Players, err = parseInt8(val)
+		case "win":
+			rs.MarblesToWin, err = parseInt8(val)
+		case "line":
+			rs.MaxLine, err = parseInt8(val)
+		case "broadside":
+			rs.Broadside, err = parseOnOff(val)
+		case "selfeject":
+			rs.SelfEjectLoses, err = parseOnOff(val)
+		case "push":
+			rs.Pushes, err = parsePushes(val)
+		case "limit":
+			rs.MoveLimit, err = parseLimit(val)
+		default:
+			err = fmt.Errorf("unknown key")
+		}
+		if err != nil {
+			return RuleSet{}, fmt.Errorf("board: rules: bad field %q: %v", field, err)
+		}
+	}
+	return rs, rs.Validate()
+}
+
+// Rules 返回本局使用的规则（副本）
+func (g *Game) Rules() RuleSet {
+	rs := g.rules
+	rs.Pushes = append([]PushRatio(nil), rs.Pushes...)
+	return rs
+}
+
+// ---- 解析辅助 ----
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+func parseOnOff(s string) (bool, error) {
+	switch s {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("want on or off")
+}
+
+func parseInt8(s string) (int8, error) {
+	v, err := strconv.ParseInt(s, 10, 8)
+	return int8(v), err
+}
+
+func parsePushes(s string) ([]PushRatio, error) {
+	out := []PushRatio{}
+	if s == "" {
+		return out, nil // 不允许推子
+	}
+	for _, part := range strings.Split(s, ",") {
+		a, d, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("want attackers:defenders")
+		}
+		av, err := parseInt8(a)
+		if err != nil {
+			return nil, err
+		}
+		dv, err := parseInt8(d)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, PushRatio{av, dv})
+	}
+	return out, nil
+}
+
+func parseLimit(s string) (MoveLimit, error) {
+	num, draw := strings.CutSuffix(s, "/draw")
+	n, err := strconv.Atoi(num)
+	if err != nil {
+		return MoveLimit{}, err
+	}
+	return MoveLimit{Moves: n, ByMarbles: !draw}, nil
+}
+
+// rulesOrStandard 零值 Game（如 ParsePosition）尚无规则时按标准规则处理
+func (g *Game) rulesOrStandard() RuleSet {
+	if g.rules.MaxLine == 0 {
+		return StandardRules()
+	}
+	return g.rules
+}
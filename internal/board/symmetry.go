@@ -0,0 +1,288 @@
+// File internal/board/symmetry.go
+package board
+
+import (
+	"fmt"
+	"sort"
+
+	"abalone_go/internal/zobrist"
+)
+
+// 六边形棋盘有 12 个对称：绕中心旋转 k·60°（k=0..5），以及先沿一条对角线翻转再旋转。
+// 两人局若双方开局摆法互为镜像（如内置摆法、大部分雏菊开局），交换颜色也是一个对称，
+// 合起来共 24 个。用立方坐标（x=c-side, z=r-side, y=-x-z）表示：
+//
+//	旋转 60°：(x, y, z) → (-z, -x, -y)
+//	翻转：    (x, y, z) → (x, z, y)
+
+// Symmetry 一个对称变换：低位 0..11 为几何变换（≥6 表示先翻转），SwapColours 位表示交换 A/B
+type Symmetry uint8
+
+const (
+	NumSymmetries = 12 // 几何对称个数
+
+	SwapColours Symmetry = 1 << 4
+)
+
+// Identity 恒等变换
+const Identity Symmetry = 0
+
+func (s Symmetry) geometry() int   { return int(s &^ SwapColours) }
+func (s Symmetry) swapped() bool   { return s&SwapColours != 0 }
+func (s Symmetry) reflected() bool { return s.geometry() >= 6 }
+
+// Inverse 逆变换：翻转类变换与交换颜色都是对合，只有纯旋转需要反向
+func (s Symmetry) Inverse() Symmetry {
+	if s.reflected() {
+		return s
+	}
+	return Symmetry((6-s.geometry())%6) | s&SwapColours
+}
+
+func (s Symmetry) String() string {
+	out := fmt.Sprintf("rot%d", s.geometry()%6*60)
+	if s.reflected() {
+		out = "flip+" + out
+	}
+	if s.swapped() {
+		out += "+swap"
+	}
+	return out
+}
+
+// cube 对立方坐标做几何变换
+func (s Symmetry) cube(x, y, z int8) (int8, int8, int8) {
+	if s.reflected() {
+		y, z = z, y
+	}
+	for k := 0; k < s.geometry()%6; k++ {
+		x, y, z = -z, -x, -y
+	}
+	return x, y, z
+}
+
+// ---- 预计算表 ----
+
+// symPos[side][s][pos] 几何变换后的格子；symDir[s][dir] 变换后的方向
+var symPos, symDir = buildSymmetryTables()
+
+func buildSymmetryTables() (pos [MaxSide + 1][NumSymmetries][MaxN]int8, dir [NumSymmetries][6]int8) {
+	for s := Symmetry(0); s < NumSymmetries; s++ {
+		for d, a := range ACTIONS {
+			dx, dz := a[1], a[0]
+			tx, _, tz := s.cube(dx, -dx-dz, dz)
+			for e, b := range ACTIONS {
+				if b[1] == tx && b[0] == tz {
+					dir[s][d] = int8(e)
+				}
+			}
+		}
+	}
+	for side := int8(StandardSide); side <= MaxSide; side++ {
+		g := &Game{rules: RuleSet{BoardSide: side}}
+		g.initCoordTables()
+		for s := Symmetry(0); s < NumSymmetries; s++ {
+			for p := int8(0); p < g.cells; p++ {
+				r, c := g.PosToCoord(p)
+				x, z := c-side, r-side
+				tx, _, tz := s.cube(x, -x-z, z)
+				pos[side][s][p] = g.CoordToPos(tz+side, tx+side)
+			}
+		}
+	}
+	return
+}
+
+// ---- 变换 ----
+
+// TransformPos 返回格子 pos 在变换 s 下的像
+func (g *Game) TransformPos(s Symmetry, pos int8) int8 { return symPos[g.side][s.geometry()][pos] }
+
+// TransformDir 返回方向 dir 在变换 s 下的像
+func TransformDir(s Symmetry, dir int8) int8 { return symDir[s.geometry()][dir] }
+
+// transformToken 交换颜色时 A/B 互换；只对两人局有意义（见 checkSwap）
+func transformToken(s Symmetry, tok int8) int8 {
+	if s.swapped() && tok >= 0 {
+		return tok ^ 1
+	}
+	return tok
+}
+
+// checkSwap 交换颜色只定义了 A↔B，3/4 人局用 tok^1 会把 C/D 也搅乱，直接 panic
+func (g *Game) checkSwap(s Symmetry) {
+	if s.swapped() && g.rules.Players != 2 {
+		panic(fmt.Sprintf("board: %s needs a 2-player game, have %d players", s, g.rules.Players))
+	}
+}
+
+// TransformMove 把 g 中的走法变换为 g.Transform(s) 中对应的走法；
+// 与 Transform 一样，交换颜色只能用于两人局
+func (g *Game) TransformMove(s Symmetry, m Move) Move {
+	g.checkSwap(s)
+	out := Move{Kind: m.Kind, Dir: TransformDir(s, m.Dir)}
+	out.Group = make([]int8, len(m.Group))
+	for i, p := range m.Group {
+		out.Group[i] = g.TransformPos(s, p)
+	}
+	// 三个正方向都让格子编号变大，升序即沿轴正方向
+	sort.Slice(out.Group, func(i, j int) bool { return out.Group[i] < out.Group[j] })
+	out.Mods = make([]Modification, len(m.Mods))
+	for i, md := range m.Mods {
+		out.Mods[i] = Modification{
+			OldPos:   g.TransformPos(s, md.OldPos),
+			NewPos:   -1,
+			DirIndex: md.DirIndex,
+			Piece:    transformToken(s, md.Piece),
+		}
+		if md.NewPos >= 0 {
+			out.Mods[i].NewPos = g.TransformPos(s, md.NewPos)
+		}
+		if md.DirIndex >= 0 {
+			out.Mods[i].DirIndex = TransformDir(s, md.DirIndex)
+		}
+	}
+	return out
+}
+
+// Symmetries 本局可用的全部变换：12 个几何变换；颜色对称的两人局再加 12 个交换颜色。
+// 3/4 人局从不交换颜色，只有 12 个几何变换。
+func (g *Game) Symmetries() []Symmetry {
+	out := make([]Symmetry, 0, 2*NumSymmetries)
+	for s := Symmetry(0); s < NumSymmetries; s++ {
+		out = append(out, s)
+	}
+	if g.colourSymmetric() {
+		for s := Symmetry(0); s < NumSymmetries; s++ {
+			out = append(out, s|SwapColours)
+		}
+	}
+	return out
+}
+
+// colourSymmetric 两人局、双方让子吃子数相同，且某个几何变换把 A 的开局摆法恰好映到 B 的（反之亦然）
+func (g *Game) colourSymmetric() bool {
+	h := g.handicap.HeadStart
+	if g.rules.Players != 2 || len(g.layout[PlayerA]) != len(g.layout[PlayerB]) || h[PlayerA] != h[PlayerB] {
+		return false
+	}
+	set := func(cells []int8) map[int8]bool {
+		m := make(map[int8]bool, len(cells))
+		for _, p := range cells {
+			m[p] = true
+		}
+		return m
+	}
+	a, b := set(g.layout[PlayerA]), set(g.layout[PlayerB])
+	for s := Symmetry(0); s < NumSymmetries; s++ {
+		ok := true
+		for p := range a {
+			if !b[g.TransformPos(s, p)] {
+				ok = false
+				break
+			}
+		}
+		for p := range b {
+			if ok && !a[g.TransformPos(s, p)] {
+				ok = false
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Transform 返回变换 s 下的新局面；规则、回合数与结果保留，
+// 历史栈与重复记录不随之变换，新局面从当前局面重新开始计数。
+// 交换颜色（SwapColours）只用于两人局，3/4 人局传入会 panic。
+func (g *Game) Transform(s Symmetry) *Game {
+	g.checkSwap(s)
+	t := g.Clone()
+	t.history = nil
+	for p := int8(0); p < g.cells; p++ {
+		q := g.TransformPos(s, p)
+		r, c := t.PosToCoord(q)
+		t.Cells[r][c] = transformToken(s, g.TokenAt(p))
+	}
+	t.syncBitboards()
+
+	t.layout = g.transformSets(s, g.layout)
+	if g.setup != nil {
+		t.setup = g.transformSets(s, g.setup)
+	}
+	t.handicap.Remove = make([]int8, len(g.handicap.Remove))
+	for i, p := range g.handicap.Remove {
+		t.handicap.Remove[i] = g.TransformPos(s, p)
+	}
+	if s.swapped() {
+		t.handicap.HeadStart[PlayerA], t.handicap.HeadStart[PlayerB] = g.handicap.HeadStart[PlayerB], g.handicap.HeadStart[PlayerA]
+		t.playerDamages[PlayerA], t.playerDamages[PlayerB] = g.playerDamages[PlayerB], g.playerDamages[PlayerA]
+		t.captures[PlayerA], t.captures[PlayerB] = g.captures[PlayerB], g.captures[PlayerA]
+		t.PlayerVictories[PlayerA], t.PlayerVictories[PlayerB] = g.PlayerVictories[PlayerB], g.PlayerVictories[PlayerA]
+		t.CurrentPlayer = g.CurrentPlayer ^ 1
+		if w := g.Result.Winner(); w != TokenEmpty {
+			t.Result.Outcome = winOutcomes[w^1]
+		}
+	}
+	t.rehash()
+	t.positions = []uint64{t.hash}
+	return t
+}
+
+// transformSets 变换每方的格子表（开局摆法），交换颜色时 A/B 两表互换
+func (g *Game) transformSets(s Symmetry, sets [][]int8) [][]int8 {
+	out := make([][]int8, len(sets))
+	for player, cells := range sets {
+		moved := make([]int8, len(cells))
+		for i, p := range cells {
+			moved[i] = g.TransformPos(s, p)
+		}
+		sort.Slice(moved, func(i, j int) bool { return moved[i] < moved[j] })
+		out[transformToken(s, int8(player))] = moved
+	}
+	return out
+}
+
+// ---- 规范形 ----
+
+// transformedHash 不构造新局面，直接算 g.Transform(s).Hash()
+func (g *Game) transformedHash(s Symmetry) uint64 {
+	g.checkSwap(s)
+	table := &symPos[g.side][s.geometry()]
+	var h uint64
+	for p := int8(0); p < g.cells; p++ {
+		if tok := g.TokenAt(p); tok >= 0 {
+			h = zobrist.Toggle(h, transformToken(s, tok), table[p])
+		}
+	}
+	h = zobrist.ToggleSide(h, transformToken(s, g.CurrentPlayer))
+	for p := int8(0); p < g.rules.Players; p++ {
+		h = zobrist.ToggleDamage(h, transformToken(s, p), g.playerDamages[p])
+		h = zobrist.ToggleCapture(h, transformToken(s, p), g.captures[p])
+	}
+	return h
+}
+
+// CanonicalSymmetry 返回把 g 变到规范形的变换：所有可用变换中哈希最小的那个
+func (g *Game) CanonicalSymmetry() Symmetry {
+	best, bestHash := Identity, g.hash
+	for _, s := range g.Symmetries()[1:] {
+		if h := g.transformedHash(s); h < bestHash {
+			best, bestHash = s, h
+		}
+	}
+	return best
+}
+
+// CanonicalHash 规范形的哈希：互为对称的局面得到同一个值，可用作开局库 / 置换表 / 去重的键
+func (g *Game) CanonicalHash() uint64 {
+	return g.transformedHash(g.CanonicalSymmetry())
+}
+
+// Canonical 返回规范形局面以及所用的变换；规范形中的走法用 TransformMove(s.Inverse(), m) 映回 g
+func (g *Game) Canonical() (*Game, Symmetry) {
+	s := g.CanonicalSymmetry()
+	return g.Transform(s), s
+}
@@ -0,0 +1,64 @@
+// File internal/board/symmetry_test.go
+package board
+
+import "testing"
+
+// TestCanonicalHash 局面经 12 个几何变换（颜色对称时再加交换颜色）后规范形哈希不变
+func TestCanonicalHash(t *testing.T) {
+	check := func(g *Game) {
+		syms := g.Symmetries()
+		want := NumSymmetries
+		if g.Players() == 2 { // 内置摆法与这里用到的开局都是颜色对称的
+			want *= 2
+		}
+		if len(syms) != want {
+			t.Fatalf("%s: %d symmetries, want %d", g, len(syms), want)
+		}
+		canon := g.CanonicalHash()
+		for _, s := range syms {
+			tg := g.Transform(s)
+			if err := tg.Validate(); err != nil {
+				t.Fatalf("%s: %s: %v", g, s, err)
+			}
+			if h := g.transformedHash(s); h != tg.Hash() {
+				t.Fatalf("%s: %s: transformedHash %#x, Transform().Hash() %#x", g, s, h, tg.Hash())
+			}
+			if got := tg.CanonicalHash(); got != canon {
+				t.Fatalf("%s: %s gives %s with canonical hash %#x, want %#x", g, s, tg, got, canon)
+			}
+		}
+	}
+
+	randomPositions(t, 1, 60, check)
+	for _, name := range []string{"classical", "belgian-daisy"} {
+		g, err := NewGameFromVariant(name, PlayerA, StandardRules())
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(g)
+	}
+}
+
+// TestSwapColoursMultiPlayer 3/4 人局不提供交换颜色，强行 Transform 会 panic
+func TestSwapColoursMultiPlayer(t *testing.T) {
+	for _, s := range []string{"players=3 win=4", "players=4 win=4"} {
+		rules, err := ParseRules(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := NewGame(PlayerA, rules)
+		for _, sym := range g.Symmetries() {
+			if sym&SwapColours != 0 {
+				t.Fatalf("%s: Symmetries includes %s", s, sym)
+			}
+		}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: Transform with colour swap did not panic", s)
+				}
+			}()
+			g.Transform(SwapColours)
+		}()
+	}
+}
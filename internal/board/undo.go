@@ -0,0 +1,101 @@
+// File internal/board/undo.go
+package board
+
+// Undo 记录一次 Make 之前的全部可变状态，交给 Unmake 即可原样恢复
+type Undo struct {
+	mods    []Modification
+	ejected int8 // 被推出的棋子颜色；无推出为 TokenEmpty
+
+	playerDamages   [MaxPlayers]int8
+	captures        [MaxPlayers]int8
+	currentPlayer   int8
+	turnCount       int
+	result          Result
+	positions       int // positions 的长度
+	playerVictories [MaxPlayers]int
+	hash            uint64
+}
+
+// Make 执行走法并返回撤销记录
+func (g *Game) Make(m Move) Undo {
+	return g.makeMods(m.Mods)
+}
+
+// MakeNull 让一手：只换行棋方（同步哈希），用 Unmake 撤销
+func (g *Game) MakeNull() Undo {
+	u := g.snapshot(nil)
+	g.passTurn()
+	return u
+}
+
+func (g *Game) snapshot(mods []Modification) Undo {
+	return Undo{
+		mods:            mods,
+		ejected:         TokenEmpty,
+		playerDamages:   g.playerDamages,
+		captures:        g.captures,
+		currentPlayer:   g.CurrentPlayer,
+		turnCount:       g.TurnCount,
+		result:          g.Result,
+		positions:       len(g.positions),
+		playerVictories: g.PlayerVictories,
+		hash:            g.hash,
+	}
+}
+
+func (g *Game) makeMods(mods []Modification) Undo {
+	u := g.snapshot(mods)
+	for _, m := range mods {
+		if m.NewPos == -1 {
+			u.ejected = g.TokenAt(m.OldPos)
+		}
+	}
+	g.Apply(mods)
+	return u
+}
+
+// Unmake 撤销 Make；必须按 Make 的相反顺序调用
+func (g *Game) Unmake(u Undo) {
+	// 倒序回放：后执行的先还原
+	for i := len(u.mods) - 1; i >= 0; i-- {
+		m := u.mods[i]
+		rOld, cOld := g.PosToCoord(m.OldPos)
+		if m.NewPos == -1 {
+			g.Cells[rOld][cOld] = u.ejected
+			g.pieces[u.ejected].set(g.gridBit(rOld, cOld))
+			continue
+		}
+		rNew, cNew := g.PosToCoord(m.NewPos)
+		g.movePiece(g.Cells[rNew][cNew], m.NewPos, m.OldPos)
+		g.Cells[rOld][cOld], g.Cells[rNew][cNew] = g.Cells[rNew][cNew], TokenEmpty
+	}
+	g.playerDamages = u.playerDamages
+	g.captures = u.captures
+	g.CurrentPlayer = u.currentPlayer
+	g.TurnCount = u.turnCount
+	g.Result = u.result
+	g.positions = g.positions[:u.positions]
+	g.PlayerVictories = u.playerVictories
+	g.hash = u.hash
+}
+
+// -------------------- 历史栈 -----------------------------
+
+// Play 执行走法并压入历史栈，可用 TakeBack 逐步悔棋
+func (g *Game) Play(m Move) {
+	g.history = append(g.history, g.Make(m))
+}
+
+// TakeBack 撤销历史栈顶的一步；栈空返回 false
+func (g *Game) TakeBack() bool {
+	n := len(g.history)
+	if n == 0 {
+		return false
+	}
+	g.Unmake(g.history[n-1])
+	g.history = g.history[:n-1]
+	return true
+}
+
+// HistoryLen 历史栈中已记录的步数
+func (g *Game) HistoryLen() int { return len(g.history) }
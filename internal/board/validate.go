@@ -0,0 +1,146 @@
+// File internal/board/validate.go
+package board
+
+import "fmt"
+
+// Validate 检查对局状态是否自洽，返回第一处矛盾：
+//
+//	规则合法，行棋方与回合数在范围内
+//	棋盘上只有本局玩家的棋子，位图与 Cells 一致
+//	每方在盘子数 + 被推出数 = 开局子数（已扣除让子拿掉的）
+//	吃子总数与被推出总数吻合（走出己子的那颗不算吃子，让子的 HeadStart 不对应被推出的棋子）
+//	Result 与吃子数、规则、重复次数、步数上限相符
+//	哈希与从头计算的结果相同
+//
+// 各类载入入口（文本局面、开局库）都会调用；以 -tags debug 构建时每次 Apply 之后也会检查。
+func (g *Game) Validate() error {
+	if err := g.rules.Validate(); err != nil {
+		return err
+	}
+	n := g.rules.Players
+	if g.CurrentPlayer < 0 || g.CurrentPlayer >= n {
+		return fmt.Errorf("board: invalid position: side to move %d in a %d-player game", g.CurrentPlayer, n)
+	}
+	if g.TurnCount < 1 {
+		return fmt.Errorf("board: invalid position: turn number %d", g.TurnCount)
+	}
+	if g.side != g.rules.BoardSide || int(g.cells) != cellsForSide(g.side) {
+		return fmt.Errorf("board: invalid position: board tables do not match side %d", g.rules.BoardSide)
+	}
+
+	// ---- 棋子 ----
+	var pieces [MaxPlayers]bitboard
+	for p := int8(0); p < g.cells; p++ {
+		r, c := g.PosToCoord(p)
+		tok := g.Cells[r][c]
+		if tok < TokenEmpty || tok >= n {
+			return fmt.Errorf("board: invalid position: bad token %d at %s", tok, g.CellName(p))
+		}
+		if tok >= 0 {
+			pieces[tok].set(g.gridBit(r, c))
+		}
+	}
+	if pieces != g.pieces {
+		return fmt.Errorf("board: invalid position: bitboards out of sync with cells")
+	}
+	if len(g.layout) != int(n) {
+		return fmt.Errorf("board: invalid position: layout has %d players, game has %d", len(g.layout), n)
+	}
+	var damages, captures int
+	head := g.handicap.HeadStart
+	for p := int8(0); p < n; p++ {
+		on, out, start := g.PlayerPieces(p), g.playerDamages[p], len(g.layout[p])
+		if int(on)+int(out) != start {
+			return fmt.Errorf("board: invalid position: %c has %d on board and %d ejected, started with %d",
+				'A'+p, on, out, start)
+		}
+		if g.captures[p] < head[p] || g.captures[p] > g.rules.MarblesToWin {
+			return fmt.Errorf("board: invalid position: %c captured %d marbles", 'A'+p, g.captures[p])
+		}
+		damages += int(out)
+		captures += int(g.captures[p] - head[p]) // 让子的吃子数没有对应的被推出棋子
+	}
+	if n == 2 && (g.captures[PlayerA]-head[PlayerA] > g.playerDamages[PlayerB] || g.captures[PlayerB]-head[PlayerB] > g.playerDamages[PlayerA]) {
+		return fmt.Errorf("board: invalid position: captures %d-%d exceed ejections", g.captures[PlayerA], g.captures[PlayerB])
+	}
+	selfEjected := 0
+	if g.Result.Reason == EndSelfEjection {
+		selfEjected = 1
+	}
+	if damages != captures+selfEjected {
+		return fmt.Errorf("board: invalid position: %d marbles ejected but %d captured", damages, captures)
+	}
+
+	if err := g.validateResult(); err != nil {
+		return err
+	}
+
+	// ---- 哈希 ----
+	h := g.hash
+	g.rehash()
+	if fresh := g.hash; fresh != h {
+		g.hash = h
+		return fmt.Errorf("board: invalid position: incremental hash %016x, recomputed %016x", h, fresh)
+	}
+	return nil
+}
+
+// validateResult 检查 Result 是否与局面相符
+func (g *Game) validateResult() error {
+	r := g.Result
+	invalid := func(why string) error { return fmt.Errorf("board: invalid position: result %s: %s", r, why) }
+
+	// 已够吃子数的队伍
+	leader := TokenEmpty
+	for p := int8(0); p < g.rules.Players; p++ {
+		if g.TeamCaptures(p) >= g.rules.MarblesToWin {
+			leader = g.Team(p)
+		}
+	}
+	switch {
+	case r.Outcome > WinD:
+		return invalid("unknown outcome")
+	case (r.Outcome == Ongoing) != (r.Reason == EndNone):
+		return invalid("outcome and reason disagree")
+	case r.Outcome == WinD, r.Outcome == WinC && g.rules.Players != 3:
+		return invalid("no such winner in this game")
+	case r.Winner() != TokenEmpty && g.Team(r.Winner()) != r.Winner():
+		return invalid("winner is not a team")
+	}
+
+	switch r.Reason {
+	case EndNone:
+		if leader != TokenEmpty {
+			return invalid(fmt.Sprintf("%c already has %d captures", 'A'+leader, g.rules.MarblesToWin))
+		}
+	case EndEjection:
+		if leader == TokenEmpty || leader != r.Winner() {
+			return invalid("winner does not have enough captures")
+		}
+	case EndSelfEjection:
+		if !g.rules.SelfEjectLoses || r.Winner() == TokenEmpty {
+			return invalid("self ejection is not allowed")
+		}
+	case EndRepetition:
+		if r.Outcome != Draw || g.Repetitions() < 3 {
+			return invalid("position has not occurred three times")
+		}
+	case EndMoveLimit:
+		if l := g.rules.MoveLimit; l.Moves == 0 || g.TurnCount-1 < l.Moves {
+			return invalid("move limit not reached")
+		}
+	default:
+		return invalid("unknown reason")
+	}
+	return nil
+}
+
+// debugValidate 调试构建下在 Apply 之后断言局面自洽
+func (g *Game) debugValidate() {
+	if !debugChecks {
+		return
+	}
+	if err := g.Validate(); err != nil {
+		panic(err)
+	}
+}
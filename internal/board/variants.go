@@ -0,0 +1,89 @@
+// File internal/board/variants.go
+package board
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// variants.json 来自 gym-abalone，格子编号与 posIndex 顺序一致
+//
+//go:embed variants.json
+var variantsJSON []byte
+
+type variantDef struct {
+	ID          int      `json:"id"`
+	BoardNb     int      `json:"board_nb"`
+	Players     int      `json:"players"`
+	PlayersSets [][]int8 `json:"players_sets"`
+}
+
+var (
+	variantsOnce sync.Once
+	variantDefs  map[string]variantDef
+	variantsErr  error
+)
+
+func loadVariants() (map[string]variantDef, error) {
+	variantsOnce.Do(func() {
+		variantsErr = json.Unmarshal(variantsJSON, &variantDefs)
+	})
+	return variantDefs, variantsErr
+}
+
+// Variants 返回所有可用开局名称（按字母序）
+func Variants() []string {
+	defs, err := loadVariants()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewGameFromVariant 按 variants.json 中的命名开局创建对局，
+// players_sets[i] 依次归 PlayerA、PlayerB……，人数须与 rules.Players 一致，
+// board_nb 须与 rules.BoardSide 的格子数一致。
+func NewGameFromVariant(name string, startPlayer int8, rules RuleSet) (*Game, error) {
+	defs, err := loadVariants()
+	if err != nil {
+		return nil, fmt.Errorf("board: parse variants: %w", err)
+	}
+	def, ok := defs[name]
+	if !ok {
+		return nil, fmt.Errorf("board: unknown variant %q", name)
+	}
+	if def.BoardNb != cellsForSide(rules.BoardSide) || int8(def.Players) != rules.Players {
+		return nil, fmt.Errorf("board: variant %q needs %d cells / %d players, unsupported",
+			name, def.BoardNb, def.Players)
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	if err := checkStartPlayer(startPlayer, rules); err != nil {
+		return nil, err
+	}
+	if err := checkLayout(def.PlayersSets, rules); err != nil {
+		return nil, fmt.Errorf("board: variant %q: %w", name, err)
+	}
+	g := newGameFromLayout(name, def.PlayersSets, startPlayer, rules)
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// checkStartPlayer 先手须是本局的一位玩家
+func checkStartPlayer(startPlayer int8, rules RuleSet) error {
+	if startPlayer < 0 || startPlayer >= rules.Players {
+		return fmt.Errorf("board: start player %d out of range for a %d-player game", startPlayer, rules.Players)
+	}
+	return nil
+}
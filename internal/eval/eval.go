@@ -21,26 +21,65 @@ import (
 */
 
 // ─── 权重与阈值 ───
-const (
-	switchHigh     = 2.0
-	switchLow      = 3.1
-	centerFactor   = 1.0
-	cohesionFactor = 1.0
-	materialHigh   = 200.0 // 攻击权重↑
-	materialMid    = 50.0
-
-	edgeOne           = -50.0
-	edgeZero          = -100.0
-	edgePenaltyStrong = -600.0 // 贴边即罚
-	pushBonus         = 350.0  // 略收敛
-	capturedBonus     = 5000.0 // 吃子权重，可自行调节
 
+// Params 评估权重与分段阈值；调参时每个引擎可各用一套
+type Params struct {
+	SwitchHigh   float64 // |h₁| 超过此值：只看位置，不计子数差
+	SwitchLow    float64 // |h₁| 低于此值：子数差按 MaterialHigh 计
+	Center       float64 // h₁ 中心距离
+	Cohesion     float64 // h₂ 连通块
+	MaterialHigh float64 // 攻击权重↑
+	MaterialMid  float64
+	EdgePenalty  float64 // 贴边即罚（负数）
+	PushBonus    float64 // 每个潜在推子
+	CaptureBonus float64 // 吃子权重
+}
+
+// DefaultParams 默认权重
+func DefaultParams() Params {
+	return Params{
+		SwitchHigh:   2.0,
+		SwitchLow:    3.1,
+		Center:       1.0,
+		Cohesion:     1.0,
+		MaterialHigh: 200.0,
+		MaterialMid:  50.0,
+		EdgePenalty:  -600.0,
+		PushBonus:    350.0,
+		CaptureBonus: 5000.0,
+	}
+}
+
+var defaultParams = DefaultParams()
+
+// MaxScore 评估分绝对值上限；须远低于 tt.MateValue-tt.MateBuffer（31500），
+// 否则子力悬殊的局面会被搜索和置换表当成胜负分
+const MaxScore = 30000
+
+// 子力分超过 materialKnee 后只按 1/materialDamp 增长：子力悬殊时仍分得出高低，
+// 又远离 MaxScore
+const (
+	materialKnee = 20000.0
+	materialDamp = 16.0
 )
 
+// material p 方子力：盘上棋子数，让子 HeadStart 记作多出的子
+func material(g *board.Game, p int8) float64 {
+	return float64(g.PlayerPieces(p)) + float64(g.HeadStart(p))
+}
+
+// dampMaterial 按 materialKnee / materialDamp 压缩子力分
+func dampMaterial(v float64) float64 {
+	if a := math.Abs(v); a > materialKnee {
+		return math.Copysign(materialKnee+(a-materialKnee)/materialDamp, v)
+	}
+	return v
+}
+
 // edgePenalty 返回 p 方“孤立”贴边子惩罚
-func edgePenalty(g *board.Game, p int8) float64 {
+func (w *Params) edgePenalty(g *board.Game, p int8) float64 {
 	bad := 0.0
-	for pos := int8(0); pos < board.N; pos++ {
+	for pos := int8(0); pos < g.CellCount(); pos++ {
 		if g.TokenAt(pos) != p {
 			continue
 		}
@@ -49,7 +88,7 @@ func edgePenalty(g *board.Game, p int8) float64 {
 		skip := false
 		for _, d := range board.ACTIONS {
 			rr, cc := r+d[0], c+d[1]
-			q := coordToPosSafe(g, rr, cc)
+			q := g.CoordToPos(rr, cc)
 			if q >= 0 && g.TokenAt(q) == p {
 				skip = true
 				break
@@ -61,9 +100,9 @@ func edgePenalty(g *board.Game, p int8) float64 {
 		// 判断是否直接邻接 VOID
 		for _, d := range board.ACTIONS {
 			rr, cc := r+d[0], c+d[1]
-			q := coordToPosSafe(g, rr, cc)
+			q := g.CoordToPos(rr, cc)
 			if q >= 0 && g.TokenAt(q) == board.TokenVoid {
-				bad += edgePenaltyStrong
+				bad += w.EdgePenalty
 				break
 			}
 		}
@@ -71,111 +110,160 @@ func edgePenalty(g *board.Game, p int8) float64 {
 	return bad
 }
 
-// Evaluate 计算 player 视角分数
-// Evaluate 计算 player 视角分数（正分 = 有利）
+// Evaluate 按默认权重计算 player 视角分数（正分 = 有利）
 func Evaluate(g *board.Game, player int8) int32 {
+	return defaultParams.Evaluate(g, player)
+}
+
+// Evaluate 按 w 计算 player 视角分数（正分 = 有利），截断在 ±MaxScore 以内
+func (w *Params) Evaluate(g *board.Game, player int8) int32 {
+	var v float64
+	if g.Players() > 2 {
+		v = w.evaluateMulti(g, player)
+	} else {
+		v = w.evaluateTwo(g, player)
+	}
+	return int32(math.Max(-MaxScore, math.Min(MaxScore, v)))
+}
 
+// evaluateTwo 两人局
+func (w *Params) evaluateTwo(g *board.Game, player int8) float64 {
 	opp := player ^ 1
 
-	// ---------- hCapture：已捕获子差 ----------
+	// ---------- hCapture：子力差（含让子 HeadStart） ----------
 	myPieces := float64(g.PlayerPieces(player))
 	oppPieces := float64(g.PlayerPieces(opp))
-	hCapture := capturedBonus * (myPieces - oppPieces) // 我方已多吃子 → 正分
+	hCapture := dampMaterial(w.CaptureBonus * (material(g, player) - material(g, opp))) // 我方已多吃子 → 正分
 
 	// ---------- h₁（中心距离） & hEdge ----------
 	var dist [2]float64
-	for pos := int8(0); pos < board.N; pos++ {
+	for pos := int8(0); pos < g.CellCount(); pos++ {
 		tok := g.TokenAt(pos)
 		if tok != board.PlayerA && tok != board.PlayerB {
 			continue
 		}
 		r, c := g.PosToCoord(pos)
-		x := int8(c) - 5
-		z := int8(r) - 5
+		x := c - g.Side()
+		z := r - g.Side()
 		y := -x - z
 		dist[tok] += float64((absI8(x) + absI8(y) + absI8(z)) / 2)
 	}
-	h1 := (dist[opp] - dist[player]) * centerFactor
+	h1 := (dist[opp] - dist[player]) * w.Center
 
 	// ---------- h₂（连通块差） ----------
-	populations := func(p int8) int {
-		vis := make([]bool, board.N)
-		cnt := 0
-		for pos := int8(0); pos < board.N; pos++ {
-			if vis[pos] || g.TokenAt(pos) != p {
-				continue
-			}
-			cnt++
-			q := list.New()
-			q.PushBack(pos)
-			vis[pos] = true
-			for q.Len() > 0 {
-				cur := q.Remove(q.Front()).(int8)
-				r, c := g.PosToCoord(cur)
-				for _, d := range board.ACTIONS {
-					rr, cc := r+d[0], c+d[1]
-					nb := coordToPosSafe(g, rr, cc)
-					if nb < 0 || vis[nb] || g.TokenAt(nb) != p {
-						continue
-					}
-					vis[nb] = true
-					q.PushBack(nb)
-				}
-			}
-		}
-		return cnt
-	}
-	h2 := float64(populations(player)-populations(opp)) * cohesionFactor
+	h2 := float64(populations(g, player)-populations(g, opp)) * w.Cohesion
 
 	// ---------- h₃（子数差：备用） ----------
 	h3 := myPieces - oppPieces
 
 	// ---------- hPush ----------
-	hPush := potentialPush(g, player) - potentialPush(g, opp)
+	hPush := w.potentialPush(g, player) - w.potentialPush(g, opp)
 
 	// ---------- 额外惩罚：贴边危险 ----------
-	badSelf := edgePenalty(g, player)
-	badOpp := edgePenalty(g, opp)
+	badSelf := w.edgePenalty(g, player)
+	badOpp := w.edgePenalty(g, opp)
 
 	// ---------- 综合 ----------
-	absH1 := math.Abs(h1 / centerFactor)
+	absH1 := math.Abs(h1 / w.Center)
 	switch {
-	case absH1 > switchHigh:
-		return int32(h1 + h2 + hPush + hCapture + badOpp - badSelf)
+	case absH1 > w.SwitchHigh:
+		return h1 + h2 + hPush + hCapture + badOpp - badSelf
 
-	case absH1 < switchLow:
-		return int32(h1 + hPush + hCapture + h3*materialHigh +
-			badOpp - badSelf)
+	case absH1 < w.SwitchLow:
+		return h1 + hPush + hCapture + h3*w.MaterialHigh +
+			badOpp - badSelf
 
 	default:
-		return int32(h1 + h2 + hPush + hCapture + h3*materialMid +
-			badOpp - badSelf)
+		return h1 + h2 + hPush + hCapture + h3*w.MaterialMid +
+			badOpp - badSelf
 	}
 }
 
-/* ---------- 潜在推子检测 ---------- */
+/* ---------- 多人局 ---------- */
+
+// evaluateMulti 3/4 人局：每位玩家按子数、中心距离、连通块、推子与贴边各自打分，
+// 返回己队平均分减对手平均分。两人局的 h₁ 分段开关依赖双方对比，这里不用。
+func (w *Params) evaluateMulti(g *board.Game, player int8) float64 {
+	var mine, theirs, matMine, matTheirs float64
+	var nMine, nTheirs int
+	for p := int8(0); p < g.Players(); p++ {
+		s, m := w.playerScore(g, p), material(g, p)
+		if g.IsOpponent(player, p) {
+			theirs, matTheirs = theirs+s, matTheirs+m
+			nTheirs++
+		} else {
+			mine, matMine = mine+s, matMine+m
+			nMine++
+		}
+	}
+	hCapture := dampMaterial(w.CaptureBonus * (matMine/float64(nMine) - matTheirs/float64(nTheirs)))
+	return hCapture + mine/float64(nMine) - theirs/float64(nTheirs)
+}
+
+// playerScore p 方的独立得分（越大越好），吃子分另由 evaluateMulti 按队伍计算
+func (w *Params) playerScore(g *board.Game, p int8) float64 {
+	dist := 0.0
+	for pos := int8(0); pos < g.CellCount(); pos++ {
+		if g.TokenAt(pos) != p {
+			continue
+		}
+		r, c := g.PosToCoord(pos)
+		x := c - g.Side()
+		z := r - g.Side()
+		y := -x - z
+		dist += float64((absI8(x) + absI8(y) + absI8(z)) / 2)
+	}
+	pieces := float64(g.PlayerPieces(p))
+	return w.MaterialMid*pieces - dist*w.Center -
+		float64(populations(g, p))*w.Cohesion + w.potentialPush(g, p) + w.edgePenalty(g, p)
+}
 
-// coordToPosSafe: 越界返回 -1
-func coordToPosSafe(g *board.Game, r, c int8) int8 {
-	if r < 0 || r > 10 || c < 0 || c > 10 {
-		return -1
+/* ---------- 连通块 ---------- */
+
+// populations p 方棋子的连通块数
+func populations(g *board.Game, p int8) int {
+	vis := make([]bool, g.CellCount())
+	cnt := 0
+	for pos := int8(0); pos < g.CellCount(); pos++ {
+		if vis[pos] || g.TokenAt(pos) != p {
+			continue
+		}
+		cnt++
+		q := list.New()
+		q.PushBack(pos)
+		vis[pos] = true
+		for q.Len() > 0 {
+			cur := q.Remove(q.Front()).(int8)
+			r, c := g.PosToCoord(cur)
+			for _, d := range board.ACTIONS {
+				rr, cc := r+d[0], c+d[1]
+				nb := g.CoordToPos(rr, cc)
+				if nb < 0 || vis[nb] || g.TokenAt(nb) != p {
+					continue
+				}
+				vis[nb] = true
+				q.PushBack(nb)
+			}
+		}
 	}
-	return g.CoordToPos(r, c)
+	return cnt
 }
 
+/* ---------- 潜在推子检测 ---------- */
+
 // potentialPush 检测 AAA E? □/VOID 型潜在推子
 // potentialPush 给出所有 Sumito 型阵列奖励（2vs1 / 3vs1 / 3vs2）
-func potentialPush(g *board.Game, p int8) float64 {
+func (w *Params) potentialPush(g *board.Game, p int8) float64 {
 	bonus := 0.0
-	for pos := int8(0); pos < board.N; pos++ {
+	for pos := int8(0); pos < g.CellCount(); pos++ {
 		if g.TokenAt(pos) != p {
 			continue
 		}
 		r, c := g.PosToCoord(pos)
 		for _, d := range board.ACTIONS {
 			// 先取同向 3 格
-			a1 := coordToPosSafe(g, r+d[0], c+d[1])
-			a2 := coordToPosSafe(g, r+2*d[0], c+2*d[1])
+			a1 := g.CoordToPos(r+d[0], c+d[1])
+			a2 := g.CoordToPos(r+2*d[0], c+2*d[1])
 			if a1 < 0 { // 至少得有 2 连
 				continue
 			}
@@ -188,23 +276,23 @@ func potentialPush(g *board.Game, p int8) float64 {
 				}
 			}
 			// 敌方首格位置
-			e1 := coordToPosSafe(g, r+int8(lenFriend)*d[0], c+int8(lenFriend)*d[1])
-			if e1 < 0 || g.TokenAt(e1) != (p^1) {
+			e1 := g.CoordToPos(r+int8(lenFriend)*d[0], c+int8(lenFriend)*d[1])
+			if e1 < 0 || !g.IsOpponent(p, g.TokenAt(e1)) {
 				continue
 			}
 			// 第二个敌子（仅 3vs2 用）
-			e2 := coordToPosSafe(g, r+int8(lenFriend+1)*d[0], c+int8(lenFriend+1)*d[1])
+			e2 := g.CoordToPos(r+int8(lenFriend+1)*d[0], c+int8(lenFriend+1)*d[1])
 			lenEnemy := 1
-			if lenFriend == 3 && e2 >= 0 && g.TokenAt(e2) == (p^1) {
+			if lenFriend == 3 && e2 >= 0 && g.IsOpponent(p, g.TokenAt(e2)) {
 				lenEnemy = 2
 			}
 			// 末尾必须为空格 / VOID / 越界 (表示可推进)
-			tail := coordToPosSafe(g, r+int8(lenFriend+lenEnemy)*d[0], c+int8(lenFriend+lenEnemy)*d[1])
+			tail := g.CoordToPos(r+int8(lenFriend+lenEnemy)*d[0], c+int8(lenFriend+lenEnemy)*d[1])
 			if tail >= 0 && g.TokenAt(tail) != board.TokenEmpty && g.TokenAt(tail) != board.TokenVoid {
 				continue
 			}
 			// Sumito 合法
-			bonus += pushBonus
+			bonus += w.PushBonus
 		}
 	}
 	return bonus
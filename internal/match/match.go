@@ -0,0 +1,269 @@
+// File internal/match/match.go
+//
+// Package match 把多局对局串成一场比赛：N 局中领先到无法追上（BestOf）或先胜 K 局（FirstTo）。
+// 每局轮换座位（两人局即交换颜色，先手方随之交替），记录比分与每局棋谱。
+// GUI、命令行与引擎对战都用同一套流程推进：
+//
+//	m, _ := match.New(match.Config{Names: []string{"alice", "bob"}, Rules: board.StandardRules(), BestOf: 3})
+//	for {
+//		g := m.Game()
+//		for !g.Result.Over() {
+//			m.Play(<m.Name(g.CurrentPlayer) 选的一步>)
+//		}
+//		if !m.Next() {
+//			break
+//		}
+//	}
+//	fmt.Println(m)
+package match
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"abalone_go/internal/board"
+	"abalone_go/internal/record"
+)
+
+// Config 比赛设置
+type Config struct {
+	Names    []string      // 参赛方，个数等于 Rules.Players；第一局依次执 A、B、C、D
+	Rules    board.RuleSet // 每局的规则
+	Variant  string        // variants.json 中的开局；空为内置摆法
+	Setup    board.Setup   // 自定义摆法，优先于 Variant
+	Position string        // 自定义起始局面（board 文本局面），优先于 Variant
+	BestOf   int           // 共 N 局，领先到剩余局数无法追上即结束；与 FirstTo 二选一
+	FirstTo  int           // 先胜 K 局者赢得比赛
+	Event    string        // 写入每局棋谱的 Event 标签
+	Tags     []record.Tag  // 每局棋谱额外的标签，如 Engine / TimeControl
+}
+
+// Match 一场比赛：当前对局、已结束各局的棋谱与比分
+type Match struct {
+	cfg     Config
+	game    *board.Game
+	rec     *record.Game
+	records []*record.Game // 已结算的各局
+	wins    []int          // 每位参赛方的胜局数；团队局队友同胜
+	draws   int
+}
+
+var errFormat = errors.New("match: exactly one of best-of and first-to must be set")
+
+// New 校验设置并开始第一局
+func New(cfg Config) (*Match, error) {
+	if err := cfg.Rules.Validate(); err != nil {
+		return nil, err
+	}
+	if len(cfg.Names) != int(cfg.Rules.Players) {
+		return nil, fmt.Errorf("match: %d names for a %d-player game", len(cfg.Names), cfg.Rules.Players)
+	}
+	if (cfg.BestOf > 0) == (cfg.FirstTo > 0) || cfg.BestOf < 0 || cfg.FirstTo < 0 {
+		return nil, errFormat
+	}
+
+	g := board.NewGame(board.PlayerA, cfg.Rules)
+	var err error
+	switch {
+	case cfg.Setup != nil:
+		g, err = board.NewGameFromSetup(cfg.Setup, board.PlayerA, cfg.Rules)
+	case cfg.Variant != "":
+		g, err = board.NewGameFromVariant(cfg.Variant, board.PlayerA, cfg.Rules)
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := &Match{cfg: cfg, game: g, wins: make([]int, len(cfg.Names))}
+	if err := m.start(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// start 把 m.game 摆回开局并新建棋谱
+func (m *Match) start() error {
+	m.game.Reset(board.PlayerA)
+	if m.cfg.Position != "" {
+		if err := m.game.UnmarshalText([]byte(m.cfg.Position)); err != nil {
+			return err
+		}
+	}
+	m.rec = record.New(m.game)
+	if m.cfg.Position != "" {
+		m.rec.SetTag(record.TagPosition, m.cfg.Position)
+	}
+	if m.cfg.Event != "" {
+		m.rec.SetTag(record.TagEvent, m.cfg.Event)
+	}
+	for _, t := range m.cfg.Tags {
+		m.rec.SetTag(t.Name, t.Value)
+	}
+	m.rec.SetTag(record.TagRound, strconv.Itoa(m.Round()))
+	seatTags := []string{record.TagPlayerA, record.TagPlayerB, record.TagPlayerC, record.TagPlayerD}
+	for c := int8(0); c < m.cfg.Rules.Players; c++ {
+		m.rec.SetTag(seatTags[c], m.Name(c))
+	}
+	return nil
+}
+
+// ---- 当前对局 ----
+
+// Game 当前这局；整场比赛共用同一个 *board.Game，换局时原地 Reset
+func (m *Match) Game() *board.Game { return m.game }
+
+// Record 当前这局的棋谱
+func (m *Match) Record() *record.Game { return m.rec }
+
+// Round 当前是第几局，从 1 起
+func (m *Match) Round() int { return len(m.records) + 1 }
+
+// Seat 参赛方 i 在当前这局执哪一色：每局轮换一位
+func (m *Match) Seat(i int) int8 {
+	n := len(m.cfg.Names)
+	return int8((i + len(m.records)) % n)
+}
+
+// Participant 当前这局执 colour 的参赛方下标
+func (m *Match) Participant(colour int8) int {
+	n := len(m.cfg.Names)
+	return ((int(colour)-len(m.records))%n + n) % n
+}
+
+// Name 当前这局执 colour 的参赛方名字
+func (m *Match) Name(colour int8) string { return m.cfg.Names[m.Participant(colour)] }
+
+// Play 记入棋谱并走一步（压入历史栈，可 TakeBack）；返回棋谱中的这一步，便于补注释
+func (m *Match) Play(mv board.Move) *record.Move {
+	r := m.rec.Add(m.game, mv)
+	m.game.Play(mv)
+	return r
+}
+
+// ---- 结算 ----
+
+// Next 结算已经结束的当前局；比赛尚未分出胜负时开始下一局并返回 true。
+// 当前局未结束时返回 false 且不做任何事
+func (m *Match) Next() bool {
+	if !m.game.Result.Over() || m.Over() {
+		return false
+	}
+	m.rec.SetResultFrom(m.game)
+	if w := m.game.Result.Winner(); w != board.TokenEmpty {
+		for c := int8(0); c < m.cfg.Rules.Players; c++ {
+			if m.game.Team(c) == w {
+				m.wins[m.Participant(c)]++
+			}
+		}
+	} else {
+		m.draws++
+	}
+	m.records = append(m.records, m.rec)
+	if m.Over() {
+		return false
+	}
+	if err := m.start(); err != nil {
+		panic(err) // 第一局已用同样的设置开过局
+	}
+	return true
+}
+
+// Played 已结算的局数
+func (m *Match) Played() int { return len(m.records) }
+
+// Over 比赛是否已分出结果
+func (m *Match) Over() bool {
+	if m.cfg.FirstTo > 0 {
+		for _, w := range m.wins {
+			if w >= m.cfg.FirstTo {
+				return true
+			}
+		}
+		return false
+	}
+	left := m.cfg.BestOf - m.Played()
+	if left <= 0 {
+		return true
+	}
+	// 领先者在剩余局数全输的情况下仍不会被任何对手追上
+	lead := m.leader()
+	if lead < 0 {
+		return false
+	}
+	for i, w := range m.wins {
+		if !m.teammates(i, lead) && w+left >= m.wins[lead] {
+			return false
+		}
+	}
+	return true
+}
+
+// Winner 比赛胜方的参赛方下标（团队赛为该队第一位）；未结束或平局返回 -1
+func (m *Match) Winner() int {
+	if !m.Over() {
+		return -1
+	}
+	return m.leader()
+}
+
+// leader 胜局最多的参赛方；与对手并列时返回 -1
+func (m *Match) leader() int {
+	best := 0
+	for i, w := range m.wins {
+		if w > m.wins[best] {
+			best = i
+		}
+	}
+	for i, w := range m.wins {
+		if w == m.wins[best] && !m.teammates(i, best) {
+			return -1
+		}
+	}
+	return best
+}
+
+// teammates 4 人局里每局轮换一位，参赛方 0/2 与 1/3 始终同队
+func (m *Match) teammates(i, j int) bool {
+	return i == j || (m.cfg.Rules.Players == 4 && (i-j)%2 == 0)
+}
+
+// Score 每位参赛方的胜局数
+func (m *Match) Score() []int { return append([]int(nil), m.wins...) }
+
+// Draws 和局数
+func (m *Match) Draws() int { return m.draws }
+
+// Records 已结算各局的棋谱，外加尚未结算的当前局
+func (m *Match) Records() []*record.Game {
+	out := append([]*record.Game(nil), m.records...)
+	if !m.Over() {
+		out = append(out, m.rec)
+	}
+	return out
+}
+
+// Format 赛制，如 "best of 5" / "first to 3"
+func (m *Match) Format() string {
+	if m.cfg.FirstTo > 0 {
+		return fmt.Sprintf("first to %d", m.cfg.FirstTo)
+	}
+	return fmt.Sprintf("best of %d", m.cfg.BestOf)
+}
+
+// String 比分摘要，如 "alice 2 - bob 1, 1 draw (best of 5)"
+func (m *Match) String() string {
+	parts := make([]string, len(m.cfg.Names))
+	for i, name := range m.cfg.Names {
+		parts[i] = fmt.Sprintf("%s %d", name, m.wins[i])
+	}
+	s := strings.Join(parts, " - ")
+	switch m.draws {
+	case 0:
+	case 1:
+		s += ", 1 draw"
+	default:
+		s += fmt.Sprintf(", %d draws", m.draws)
+	}
+	return fmt.Sprintf("%s (%s)", s, m.Format())
+}
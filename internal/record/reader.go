@@ -0,0 +1,221 @@
+// File internal/record/reader.go
+package record
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Reader 流式读取棋谱，一次一局
+type Reader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r), line: 1}
+}
+
+// Read 读取下一局；没有更多棋谱时返回 io.EOF。
+// 只做语法检查，走法合法性由 Game.Replay 校验。
+func (rd *Reader) Read() (*Game, error) {
+	rec := &Game{}
+	started := false
+	for {
+		c, err := rd.readByte()
+		if err == io.EOF {
+			if !started {
+				return nil, io.EOF
+			}
+			return nil, rd.errorf("unexpected end of input, missing result")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case isSpace(c):
+			continue
+
+		case c == '[':
+			if len(rec.Moves) > 0 {
+				return nil, rd.errorf("tag after move text")
+			}
+			started = true
+			t, err := rd.readTag()
+			if err != nil {
+				return nil, err
+			}
+			rec.SetTag(t.Name, t.Value)
+
+		case c == '{':
+			started = true
+			text, err := rd.readUntil('}')
+			if err != nil {
+				return nil, err
+			}
+			if len(rec.Moves) > 0 { // 走法前的注释直接忽略
+				parseComment(&rec.Moves[len(rec.Moves)-1], text)
+			}
+
+		case c == ';': // 行注释
+			if _, err := rd.readUntil('\n'); err != nil && err != io.EOF {
+				return nil, err
+			}
+
+		default:
+			started = true
+			tok, err := rd.readToken(c)
+			if err != nil {
+				return nil, err
+			}
+			switch tok {
+			case ResultAWins, ResultBWins, ResultDraw, ResultUnknown,
+				Result3AWins, Result3BWins, Result3CWins:
+				if r := rec.Tag(TagResult); r != "" && r != tok {
+					return nil, rd.errorf("result %s does not match %s tag %q", tok, TagResult, r)
+				}
+				rec.SetTag(TagResult, tok)
+				return rec, nil
+			}
+			if tok = stripMoveNumber(tok); tok != "" {
+				rec.Moves = append(rec.Moves, Move{Text: tok})
+			}
+		}
+	}
+}
+
+// readTag 解析 Name "Value"]，调用前已读入 '['
+func (rd *Reader) readTag() (Tag, error) {
+	body, err := rd.readQuotedUntil(']')
+	if err != nil {
+		return Tag{}, err
+	}
+	name, value, ok := strings.Cut(strings.TrimSpace(body), " ")
+	value = strings.TrimSpace(value)
+	if !ok || name == "" || len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return Tag{}, rd.errorf("malformed tag [%s]", body)
+	}
+	value, err = strconv.Unquote(value)
+	if err != nil {
+		return Tag{}, rd.errorf("malformed tag value in [%s]", body)
+	}
+	return Tag{Name: name, Value: value}, nil
+}
+
+// readQuotedUntil 读到 end 为止，忽略双引号字符串内部的 end
+func (rd *Reader) readQuotedUntil(end byte) (string, error) {
+	var sb strings.Builder
+	inQuote, escaped := false, false
+	for {
+		c, err := rd.readByte()
+		if err != nil {
+			return "", rd.errorf("unterminated tag")
+		}
+		switch {
+		case escaped:
+			escaped = false
+		case inQuote && c == '\\':
+			escaped = true
+		case c == '"':
+			inQuote = !inQuote
+		case c == end && !inQuote:
+			return sb.String(), nil
+		case c == '\n':
+			return "", rd.errorf("unterminated tag")
+		}
+		sb.WriteByte(c)
+	}
+}
+
+func (rd *Reader) readUntil(end byte) (string, error) {
+	var sb strings.Builder
+	for {
+		c, err := rd.readByte()
+		if err == io.EOF {
+			return sb.String(), rd.errorf("missing %q", end)
+		}
+		if err != nil {
+			return "", err
+		}
+		if c == end {
+			return sb.String(), nil
+		}
+		sb.WriteByte(c)
+	}
+}
+
+// readToken 读到空白或注释/标签起始符为止
+func (rd *Reader) readToken(first byte) (string, error) {
+	var sb strings.Builder
+	sb.WriteByte(first)
+	for {
+		c, err := rd.readByte()
+		if err == io.EOF {
+			return sb.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if isSpace(c) || c == '{' || c == '[' || c == ';' {
+			rd.unreadByte(c)
+			return sb.String(), nil
+		}
+		sb.WriteByte(c)
+	}
+}
+
+func (rd *Reader) readByte() (byte, error) {
+	c, err := rd.r.ReadByte()
+	if c == '\n' && err == nil {
+		rd.line++
+	}
+	return c, err
+}
+
+func (rd *Reader) unreadByte(c byte) {
+	if c == '\n' {
+		rd.line--
+	}
+	rd.r.UnreadByte()
+}
+
+func (rd *Reader) errorf(format string, args ...any) error {
+	return fmt.Errorf("record: line %d: %s", rd.line, fmt.Sprintf(format, args...))
+}
+
+// stripMoveNumber 去掉 "12." / "12..." 前缀，"1.A1B2" 也能识别
+func stripMoveNumber(tok string) string {
+	i := 0
+	for i < len(tok) && tok[i] >= '0' && tok[i] <= '9' {
+		i++
+	}
+	if i == 0 || i == len(tok) || tok[i] != '.' {
+		return tok
+	}
+	return strings.TrimLeft(tok[i:], ".")
+}
+
+// parseComment 拆出 [%eval N]，其余作为注释文本
+func parseComment(m *Move, text string) {
+	text = strings.TrimSpace(text)
+	if rest, ok := strings.CutPrefix(text, "[%eval "); ok {
+		if num, tail, ok := strings.Cut(rest, "]"); ok {
+			if v, err := strconv.ParseInt(strings.TrimSpace(num), 10, 32); err == nil {
+				m.HasEval, m.Eval = true, int32(v)
+				text = strings.TrimSpace(tail)
+			}
+		}
+	}
+	if m.Comment != "" && text != "" {
+		m.Comment += " "
+	}
+	m.Comment += text
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,243 @@
+// File internal/record/record.go
+//
+// Package record 实现类似 PGN 的 Abalone 棋谱格式：
+//
+//	[Event "Club night"]
+//	[Date "2026.10.16"]
+//	[PlayerA "alice"]
+//	[PlayerB "abalone_go"]
+//	[Variant "belgian-daisy"]
+//	[StartPlayer "A"]
+//	[Rules "side=5 players=3 win=6 line=3 broadside=on push=2:1,3:1,3:2 selfeject=off limit=200"]
+//	[Result "0-1"]
+//	[TimeControl "15s"]
+//	[Engine "depth=4"]
+//
+//	1. A1B2 I5H5 {[%eval -35] 守中} 2. A3A5B4 ... 0-1
+//
+// 走法使用 board 包的标准记法；{} 内为注释，其中 [%eval N] 记录引擎评估。
+// 轮号按人数计：从 StartPlayer 起各方各走一步为一轮，3 人局每 3 步一个轮号。
+// 每局以结果标记（1-0 / 0-1 / 1/2-1/2 / *）结束，一个文件可连续存放多局。
+// 3 人局的胜负写作 1-0-0 / 0-1-0 / 0-0-1；4 人局按队伍记，A+C 胜为 1-0。
+package record
+
+import (
+	"fmt"
+	"time"
+
+	"abalone_go/internal/board"
+)
+
+// 常用标签名
+const (
+	TagEvent       = "Event"
+	TagDate        = "Date"
+	TagPlayerA     = "PlayerA"
+	TagPlayerB     = "PlayerB"
+	TagPlayerC     = "PlayerC" // 仅 3/4 人局
+	TagPlayerD     = "PlayerD"
+	TagRound       = "Round" // 比赛中的第几局
+	TagVariant     = "Variant"
+	TagStartPlayer = "StartPlayer"
+	TagPosition    = "Position" // 自定义起始局面（board 文本局面）
+	TagRules       = "Rules"    // 非标准规则（board.RuleSet 文本）；缺省为标准规则
+	TagSetup       = "Setup"    // 自定义摆法（board.Setup 的 JSON），取代 Variant
+	TagHandicap    = "Handicap" // 让子（board.Game.FormatHandicap 文本）
+	TagResult      = "Result"
+	TagTimeControl = "TimeControl"
+	TagEngine      = "Engine"
+)
+
+// 结果标记
+const (
+	ResultAWins   = "1-0"
+	ResultBWins   = "0-1"
+	ResultDraw    = "1/2-1/2"
+	ResultUnknown = "*"
+
+	Result3AWins = "1-0-0"
+	Result3BWins = "0-1-0"
+	Result3CWins = "0-0-1"
+)
+
+// Tag 头部标签，按写入顺序保存
+type Tag struct {
+	Name, Value string
+}
+
+// Move 棋谱中的一步
+type Move struct {
+	Text    string // 标准记法，如 "A1B2"
+	Comment string
+	HasEval bool
+	Eval    int32 // 引擎评估（行棋方视角）
+}
+
+// Game 一盘棋谱
+type Game struct {
+	Tags  []Tag
+	Moves []Move
+}
+
+// New 按对局的起始状态生成棋谱头；应在第一步之前调用
+func New(g *board.Game) *Game {
+	rec := &Game{}
+	rec.SetTag(TagDate, time.Now().Format("2006.01.02"))
+	if v := g.Variant(); v != "" {
+		rec.SetTag(TagVariant, v)
+	}
+	if setup := g.Setup(); setup != nil {
+		rec.SetTag(TagSetup, setup.String())
+	}
+	rec.SetTag(TagStartPlayer, playerName(g.CurrentPlayer))
+	if rules := g.Rules(); !rules.Equal(board.StandardRules()) {
+		rec.SetTag(TagRules, rules.String())
+	}
+	if h := g.Handicap(); !h.IsZero() {
+		rec.SetTag(TagHandicap, g.FormatHandicap(h))
+	}
+	rec.SetTag(TagResult, ResultUnknown)
+	return rec
+}
+
+// Tag 返回标签值；不存在时为空串
+func (rec *Game) Tag(name string) string {
+	for _, t := range rec.Tags {
+		if t.Name == name {
+			return t.Value
+		}
+	}
+	return ""
+}
+
+// SetTag 设置标签；已存在则原地覆盖
+func (rec *Game) SetTag(name, value string) {
+	for i := range rec.Tags {
+		if rec.Tags[i].Name == name {
+			rec.Tags[i].Value = value
+			return
+		}
+	}
+	rec.Tags = append(rec.Tags, Tag{name, value})
+}
+
+// Add 在 g 执行 m 之前调用，按当前局面格式化并追加
+func (rec *Game) Add(g *board.Game, m board.Move) *Move {
+	rec.Moves = append(rec.Moves, Move{Text: g.FormatMove(m)})
+	return &rec.Moves[len(rec.Moves)-1]
+}
+
+// players 按 Rules 标签取人数；缺省或无法解析时为 2
+func (rec *Game) players() int {
+	if s := rec.Tag(TagRules); s != "" {
+		if rules, err := board.ParseRules(s); err == nil {
+			return int(rules.Players)
+		}
+	}
+	return 2
+}
+
+// Result 返回结果标签；缺省为 "*"
+func (rec *Game) Result() string {
+	if r := rec.Tag(TagResult); r != "" {
+		return r
+	}
+	return ResultUnknown
+}
+
+// SetResultFrom 根据对局胜负写入结果标签
+func (rec *Game) SetResultFrom(g *board.Game) {
+	three := g.Players() == 3
+	switch g.Result.Outcome {
+	case board.WinA:
+		rec.SetTag(TagResult, pick(three, Result3AWins, ResultAWins))
+	case board.WinB:
+		rec.SetTag(TagResult, pick(three, Result3BWins, ResultBWins))
+	case board.WinC:
+		rec.SetTag(TagResult, Result3CWins)
+	case board.Draw:
+		rec.SetTag(TagResult, ResultDraw)
+	default:
+		rec.SetTag(TagResult, ResultUnknown)
+	}
+}
+
+// Start 按头部标签创建起始局面
+func (rec *Game) Start() (*board.Game, error) {
+	rules := board.StandardRules()
+	if s := rec.Tag(TagRules); s != "" {
+		var err error
+		if rules, err = board.ParseRules(s); err != nil {
+			return nil, fmt.Errorf("record: %w", err)
+		}
+	}
+
+	start := board.PlayerA
+	if sp := rec.Tag(TagStartPlayer); sp != "" {
+		if len(sp) != 1 || sp[0] < 'A' || sp[0] >= 'A'+byte(rules.Players) {
+			return nil, fmt.Errorf("record: bad %s %q", TagStartPlayer, sp)
+		}
+		start = int8(sp[0] - 'A')
+	}
+
+	// 摆法 → 让子 → 自定义局面，依次叠加
+	g := board.NewGame(start, rules)
+	var err error
+	if s := rec.Tag(TagSetup); s != "" {
+		var setup board.Setup
+		if setup, err = board.ParseSetup(s); err == nil {
+			g, err = board.NewGameFromSetup(setup, start, rules)
+		}
+	} else if v := rec.Tag(TagVariant); v != "" {
+		g, err = board.NewGameFromVariant(v, start, rules)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s := rec.Tag(TagHandicap); s != "" {
+		h, err := g.ParseHandicap(s)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.ApplyHandicap(h); err != nil {
+			return nil, err
+		}
+	}
+	if pos := rec.Tag(TagPosition); pos != "" {
+		if err := g.UnmarshalText([]byte(pos)); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// Replay 从起始局面逐步重放，每一步都做合法性校验；
+// 返回的对局带完整历史栈，可直接 TakeBack。
+func (rec *Game) Replay() (*board.Game, error) {
+	g, err := rec.Start()
+	if err != nil {
+		return nil, err
+	}
+	for i, mv := range rec.Moves {
+		if g.Result.Over() {
+			return nil, fmt.Errorf("record: move %d %s after game over", i+1, mv.Text)
+		}
+		m, err := g.ParseMove(mv.Text)
+		if err != nil {
+			return nil, fmt.Errorf("record: move %d: %w", i+1, err)
+		}
+		g.Play(m)
+	}
+	return g, nil
+}
+
+func playerName(p int8) string {
+	return string(rune('A' + p))
+}
+
+func pick(cond bool, a, b string) string {
+	if cond {
+		return a
+	}
+	return b
+}
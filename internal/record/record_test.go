@@ -0,0 +1,192 @@
+// File internal/record/record_test.go
+package record
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"abalone_go/internal/board"
+)
+
+// writeRead 写出一局再读回
+func writeRead(t *testing.T, rec *Game) (string, *Game) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).Write(rec); err != nil {
+		t.Fatal(err)
+	}
+	text := buf.String()
+	got, err := NewReader(&buf).Read()
+	if err != nil {
+		t.Fatalf("%v\n%s", err, text)
+	}
+	return text, got
+}
+
+// playRandom 随机下一盘并边下边记谱，评估与注释交替写入
+func playRandom(t *testing.T, rng *rand.Rand, rules board.RuleSet, start int8) (*board.Game, *Game) {
+	t.Helper()
+	g := board.NewGame(start, rules)
+	rec := New(g)
+	for ply := 0; ply < 200 && !g.Result.Over(); ply++ {
+		moves := board.LegalMoves(g)
+		m := moves[rng.Intn(len(moves))]
+		mv := rec.Add(g, m)
+		switch ply % 3 {
+		case 0:
+			mv.HasEval, mv.Eval = true, int32(rng.Intn(2001)-1000)
+		case 1:
+			mv.Comment = fmt.Sprintf("第 %d 步", ply+1)
+		}
+		g.Play(m)
+	}
+	rec.SetResultFrom(g)
+	return g, rec
+}
+
+// TestRoundTrip 写出 → 读回 → Replay 得到同一终局，标签与注释不变
+func TestRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, s := range []string{"", "selfeject=on", "players=3 win=4", "players=4 win=4 selfeject=on"} {
+		rules, err := board.ParseRules(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, start := range []int8{board.PlayerA, board.PlayerB} {
+			g, rec := playRandom(t, rng, rules, start)
+			text, got := writeRead(t, rec)
+			if fmt.Sprint(got.Tags) != fmt.Sprint(rec.Tags) {
+				t.Fatalf("%q: tags %v after reload, want %v", s, got.Tags, rec.Tags)
+			}
+			if len(got.Moves) != len(rec.Moves) {
+				t.Fatalf("%q: %d moves after reload, want %d\n%s", s, len(got.Moves), len(rec.Moves), text)
+			}
+			for i := range rec.Moves {
+				if got.Moves[i] != rec.Moves[i] {
+					t.Fatalf("%q: move %d reads back as %+v, want %+v", s, i+1, got.Moves[i], rec.Moves[i])
+				}
+			}
+			replayed, err := got.Replay()
+			if err != nil {
+				t.Fatalf("%q: %v\n%s", s, err, text)
+			}
+			if replayed.String() != g.String() || replayed.Result != g.Result {
+				t.Fatalf("%q: replay ends at %s (%s), want %s (%s)",
+					s, replayed, replayed.Result, g, g.Result)
+			}
+		}
+	}
+}
+
+// TestTagQuoting 标签值中的引号、反斜杠、换行等按 strconv.Quote 转义后原样读回
+func TestTagQuoting(t *testing.T) {
+	values := []string{
+		`say "hi"`,
+		`C:\games\abalone`,
+		"two\nlines",
+		"tab\there",
+		"a]b [c]",
+		"中文 ✓",
+		"",
+	}
+	rec := &Game{}
+	for i, v := range values {
+		rec.SetTag(fmt.Sprintf("Tag%d", i), v)
+	}
+	rec.SetTag(TagResult, ResultUnknown)
+
+	text, got := writeRead(t, rec)
+	for i, v := range values {
+		name := fmt.Sprintf("Tag%d", i)
+		if line := fmt.Sprintf("[%s %s]\n", name, strconv.Quote(v)); !strings.Contains(text, line) {
+			t.Errorf("%s not written as %q:\n%s", name, line, text)
+		}
+		if g := got.Tag(name); g != v {
+			t.Errorf("%s reads back as %q, want %q", name, g, v)
+		}
+	}
+}
+
+// TestCommentBrace 注释里的 } 写成 )，不会提前结束注释、吞掉后面的走法
+func TestCommentBrace(t *testing.T) {
+	g := board.NewGame(board.PlayerA, board.StandardRules())
+	rec := New(g)
+	for i := 0; i < 3; i++ {
+		m := board.LegalMoves(g)[0]
+		mv := rec.Add(g, m)
+		if i == 0 {
+			mv.HasEval, mv.Eval = true, -35
+			mv.Comment = "a } b {c}"
+		}
+		g.Play(m)
+	}
+
+	text, got := writeRead(t, rec)
+	if len(got.Moves) != len(rec.Moves) {
+		t.Fatalf("%d moves after reload, want %d\n%s", len(got.Moves), len(rec.Moves), text)
+	}
+	want := Move{Text: rec.Moves[0].Text, Comment: "a ) b {c)", HasEval: true, Eval: -35}
+	if got.Moves[0] != want {
+		t.Fatalf("first move reads back as %+v, want %+v", got.Moves[0], want)
+	}
+	if _, err := got.Replay(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestMoveNumbers 轮号按规则人数计，每轮从起始方开始
+func TestMoveNumbers(t *testing.T) {
+	cases := []struct {
+		rules string
+		want  string
+	}{
+		{"", "1. m1 m2 2. m3 m4 3. m5 m6 4. m7 *"},
+		{"players=3 win=4", "1. m1 m2 m3 2. m4 m5 m6 3. m7 *"},
+		{"players=4 win=4", "1. m1 m2 m3 m4 2. m5 m6 m7 *"},
+	}
+	for _, c := range cases {
+		rec := &Game{}
+		if c.rules != "" {
+			rec.SetTag(TagRules, c.rules)
+		}
+		rec.SetTag(TagStartPlayer, "B")
+		for i := 1; i <= 7; i++ {
+			rec.Moves = append(rec.Moves, Move{Text: fmt.Sprintf("m%d", i)})
+		}
+		var buf bytes.Buffer
+		if err := NewWriter(&buf).Write(rec); err != nil {
+			t.Fatal(err)
+		}
+		_, body, _ := strings.Cut(buf.String(), "\n\n")
+		if got := strings.Join(strings.Fields(body), " "); got != c.want {
+			t.Errorf("%q: move text %q, want %q", c.rules, got, c.want)
+		}
+	}
+}
+
+// TestReadEOF 多局连续存放，读完后返回 io.EOF
+func TestReadEOF(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	var buf bytes.Buffer
+	wr := NewWriter(&buf)
+	for i := 0; i < 3; i++ {
+		_, rec := playRandom(t, rng, board.StandardRules(), board.PlayerA)
+		if err := wr.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rd := NewReader(&buf)
+	for i := 0; i < 3; i++ {
+		if _, err := rd.Read(); err != nil {
+			t.Fatalf("game %d: %v", i+1, err)
+		}
+	}
+	if _, err := rd.Read(); err != io.EOF {
+		t.Fatalf("after last game: %v, want io.EOF", err)
+	}
+}
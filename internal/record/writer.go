@@ -0,0 +1,74 @@
+// File internal/record/writer.go
+package record
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const lineWidth = 79 // 走法区自动换行宽度
+
+// Writer 顺序写出多局棋谱
+type Writer struct {
+	w *bufio.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Write 写出一局并立即 Flush
+func (wr *Writer) Write(rec *Game) error {
+	for _, t := range rec.Tags {
+		fmt.Fprintf(wr.w, "[%s %s]\n", t.Name, strconv.Quote(t.Value)) // 换行等控制字符也转义，Reader 用 strconv.Unquote 读回
+	}
+	wr.w.WriteByte('\n')
+
+	col := 0
+	emit := func(tok string) {
+		if col > 0 && col+1+len(tok) > lineWidth {
+			wr.w.WriteByte('\n')
+			col = 0
+		}
+		if col > 0 {
+			wr.w.WriteByte(' ')
+			col++
+		}
+		wr.w.WriteString(tok)
+		col += len(tok)
+	}
+
+	// 每轮由起始方开始，各方依次走一步；轮号写在每轮第一步之前
+	players := rec.players()
+	for i, m := range rec.Moves {
+		if i%players == 0 {
+			emit(fmt.Sprintf("%d.", i/players+1))
+		}
+		emit(m.Text)
+		if c := formatComment(m); c != "" {
+			emit(c)
+		}
+	}
+	emit(rec.Result())
+	wr.w.WriteString("\n\n")
+	return wr.w.Flush()
+}
+
+// formatComment 合并评估与注释为 {...}
+func formatComment(m Move) string {
+	var parts []string
+	if m.HasEval {
+		parts = append(parts, fmt.Sprintf("[%%eval %d]", m.Eval))
+	}
+	if m.Comment != "" {
+		// } 会提前结束注释，统一替换
+		parts = append(parts, strings.ReplaceAll(m.Comment, "}", ")"))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
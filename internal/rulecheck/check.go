@@ -0,0 +1,182 @@
+// File internal/rulecheck/check.go
+package rulecheck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"abalone_go/internal/board"
+)
+
+// Check 在局面 g 上把 board 与参考实现逐项对拍，返回第一处分歧；g 不会被修改。
+//
+//	LegalMoves  与参考实现的合法走法集合相同，无重复，每步的类型与走完的局面相同
+//	GroupMove   对每个 (棋串, 方向) 的合法性判定相同，合法时结果相同
+//	ValidateMove 任意两点输入给出的走法都必须合法且结果相同；
+//	             直线走法与推子的标准两点输入（尾子, 领头子前方一格）必须被接受
+//
+// 已结束的局面不检查。
+func Check(g *board.Game) error {
+	if g.Result.Over() {
+		return nil
+	}
+	ref := readPosition(g)
+	cands := ref.candidates()
+	legal := map[string]refMove{}
+	for _, rm := range cands {
+		if rm.legal {
+			legal[refKey(g, rm)] = rm
+		}
+	}
+
+	// ---- LegalMoves ----
+	seen := map[string]bool{}
+	for _, m := range board.LegalMoves(g) {
+		k := moveKey(m.Group, m.Dir)
+		if seen[k] {
+			return fmt.Errorf("LegalMoves: %s generated twice", g.FormatMove(m))
+		}
+		seen[k] = true
+		rm, ok := legal[k]
+		if !ok {
+			return fmt.Errorf("LegalMoves: %s (%s) is not a legal move", g.FormatMove(m), m.Kind)
+		}
+		if err := compare(g, m.Kind, m.Mods, rm); err != nil {
+			return fmt.Errorf("LegalMoves: %s: %w", g.FormatMove(m), err)
+		}
+	}
+	for k, rm := range legal {
+		if !seen[k] {
+			return fmt.Errorf("LegalMoves: missing %s (%s)", describe(g, rm), rm.kind)
+		}
+	}
+
+	// ---- GroupMove ----
+	for _, rm := range cands {
+		m, err := g.GroupMove(groupPos(g, rm.group), rm.dir)
+		switch {
+		case err != nil && rm.legal:
+			return fmt.Errorf("GroupMove: %s (%s) rejected: %v", describe(g, rm), rm.kind, err)
+		case err == nil && !rm.legal:
+			return fmt.Errorf("GroupMove: %s accepted as %s but is illegal", describe(g, rm), m.Kind)
+		case err == nil:
+			if err := compare(g, m.Kind, m.Mods, rm); err != nil {
+				return fmt.Errorf("GroupMove: %s: %w", describe(g, rm), err)
+			}
+		}
+	}
+
+	// ---- ValidateMove：两点输入 ----
+	for pos0 := int8(0); pos0 < g.CellCount(); pos0++ {
+		if g.TokenAt(pos0) != g.CurrentPlayer {
+			continue
+		}
+		for pos1 := int8(0); pos1 < g.CellCount(); pos1++ {
+			kind, mods, err := g.ValidateMove(pos0, pos1)
+			if err != nil {
+				continue
+			}
+			if !matchesSome(g, kind, mods, legal) {
+				return fmt.Errorf("ValidateMove(%s, %s): %s %s matches no legal move",
+					g.CellName(pos0), g.CellName(pos1), kind, formatMods(g, mods))
+			}
+		}
+	}
+	for _, rm := range legal {
+		if rm.kind == board.KindSidestepMove || rm.kind == board.KindSelfEject {
+			continue // 两点输入无法完整表达侧移，也不能指向棋盘外
+		}
+		tail, lead := rm.group[0], rm.group[len(rm.group)-1]
+		if rm.dir >= 3 {
+			tail, lead = lead, tail
+		}
+		pos0, pos1 := posOf(g, tail), posOf(g, lead.add(hexDirs[rm.dir]))
+		kind, mods, err := g.ValidateMove(pos0, pos1)
+		if err != nil {
+			return fmt.Errorf("ValidateMove(%s, %s): %s (%s) rejected: %v",
+				g.CellName(pos0), g.CellName(pos1), describe(g, rm), rm.kind, err)
+		}
+		if err := compare(g, kind, mods, rm); err != nil {
+			return fmt.Errorf("ValidateMove(%s, %s): %w", g.CellName(pos0), g.CellName(pos1), err)
+		}
+	}
+	return nil
+}
+
+// compare 在 g 的副本上 Apply(mods)，与参考实现的结果比较
+func compare(g *board.Game, kind board.MoveKind, mods []board.Modification, rm refMove) error {
+	c, got := apply(g, mods)
+	return compareApplied(g, kind, mods, c, got, rm)
+}
+
+// apply 在 g 的副本上执行 mods，同时读出参考实现眼中的结果
+func apply(g *board.Game, mods []board.Modification) (*board.Game, *refPosition) {
+	c := g.Clone()
+	c.Apply(mods)
+	return c, readPosition(c)
+}
+
+func compareApplied(g *board.Game, kind board.MoveKind, mods []board.Modification, c *board.Game, got *refPosition, rm refMove) error {
+	if kind != rm.kind {
+		return fmt.Errorf("kind %s, rules say %s", kind, rm.kind)
+	}
+	if !got.equal(rm.after) {
+		return fmt.Errorf("Apply %s gives %s, rules say %s", formatMods(g, mods), c, positionText(g, rm.after))
+	}
+	won := c.Result.Reason == board.EndEjection || c.Result.Reason == board.EndSelfEjection
+	switch {
+	case rm.winner >= 0 && (!won || c.Result.Winner() != rm.winner):
+		return fmt.Errorf("result %s, rules say %c wins", c.Result, 'A'+rm.winner)
+	case rm.winner < 0 && won:
+		return fmt.Errorf("result %s, rules say the game goes on", c.Result)
+	}
+	return nil
+}
+
+// matchesSome 两点输入给出的走法是否与某个合法走法结果相同
+func matchesSome(g *board.Game, kind board.MoveKind, mods []board.Modification, legal map[string]refMove) bool {
+	c, got := apply(g, mods)
+	for _, rm := range legal {
+		if rm.kind == kind && got.equal(rm.after) && compareApplied(g, kind, mods, c, got, rm) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ---- 键与打印 ----
+
+func groupPos(g *board.Game, group []hex) []int8 {
+	out := make([]int8, len(group))
+	for i, h := range group {
+		out[i] = posOf(g, h)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+func moveKey(group []int8, dir int8) string { return fmt.Sprint(group, dir) }
+
+func refKey(g *board.Game, rm refMove) string { return moveKey(groupPos(g, rm.group), rm.dir) }
+
+// describe 参考走法的可读形式，如 "C3 C4 dir 1"
+func describe(g *board.Game, rm refMove) string {
+	var names []string
+	for _, p := range groupPos(g, rm.group) {
+		names = append(names, g.CellName(p))
+	}
+	return fmt.Sprintf("%s dir %d", strings.Join(names, " "), rm.dir)
+}
+
+func formatMods(g *board.Game, mods []board.Modification) string {
+	var parts []string
+	for _, m := range mods {
+		to := "out"
+		if m.NewPos >= 0 {
+			to = g.CellName(m.NewPos)
+		}
+		parts = append(parts, g.CellName(m.OldPos)+"-"+to)
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
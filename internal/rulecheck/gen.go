@@ -0,0 +1,198 @@
+// File internal/rulecheck/gen.go
+package rulecheck
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"abalone_go/internal/board"
+)
+
+// Run 把 data 解码成一局随机对局并在每一步上调用 Check，返回检查过的局面数与第一处分歧。
+// 同一份 data 总是得到同一局：`abalone fuzz` 随机生成 data，rulecheck_test.go 的 FuzzRun 把它交给 go test -fuzz。
+//
+//	规则：人数 2-4、边长、侧移、SelfEjectLoses、获胜子数、推子比例都随机
+//	开局：内置摆法 / variants.json 中的开局 / 随机撒子
+//	之后每步从 LegalMoves 中按 data 挑一步，直到 data 读完、终局或 maxPlies
+func Run(data []byte) (int, error) {
+	s := &source{data: data}
+	rules := decodeRules(s)
+	g := startPosition(s, rules)
+	checked := 0
+	for ply := 0; ply < maxPlies; ply++ {
+		if err := Check(g); err != nil {
+			return checked, fmt.Errorf("rules %q, position %s: %w", rules, g, err)
+		}
+		checked++
+		if g.Result.Over() || s.done() {
+			break
+		}
+		moves := board.LegalMoves(g)
+		if len(moves) == 0 {
+			break
+		}
+		g.Apply(moves[s.intn(len(moves))].Mods)
+	}
+	return checked, nil
+}
+
+// maxPlies 一局最多检查的步数
+const maxPlies = 200
+
+// source 把字节流当作随机数来源；读完之后一律返回 0
+type source struct {
+	data []byte
+	i    int
+}
+
+func (s *source) byte() byte {
+	if s.i >= len(s.data) {
+		return 0
+	}
+	s.i++
+	return s.data[s.i-1]
+}
+
+// intn 返回 [0,n)，n 超过 256 时读两个字节
+func (s *source) intn(n int) int {
+	v := int(s.byte())
+	if n > 256 {
+		v = v<<8 | int(s.byte())
+	}
+	return v % n
+}
+
+func (s *source) done() bool { return s.i >= len(s.data) }
+
+// decodeRules 多数情况接近标准规则，偶尔换成非常规的组合
+func decodeRules(s *source) board.RuleSet {
+	rules := board.StandardRules()
+	rules.Players = []int8{2, 2, 2, 2, 3, 4}[s.intn(6)]
+	if s.intn(4) == 0 {
+		rules.BoardSide = board.StandardSide + 1 + int8(s.intn(board.MaxSide-board.StandardSide))
+	}
+	rules.Broadside = s.intn(4) != 0
+	rules.SelfEjectLoses = rules.Players != 3 && s.intn(4) == 0
+	rules.MarblesToWin = 1 + int8(s.intn(6))
+	if s.intn(4) == 0 { // 非常规推子：最长 2-4 颗，比例随机取
+		rules.MaxLine = 2 + int8(s.intn(3))
+		rules.Pushes = nil
+		for a := int8(2); a <= rules.MaxLine; a++ {
+			for d := int8(1); d < a; d++ {
+				if s.intn(2) == 0 {
+					rules.Pushes = append(rules.Pushes, board.PushRatio{Attackers: a, Defenders: d})
+				}
+			}
+		}
+	}
+	if rules.Validate() != nil {
+		return board.StandardRules()
+	}
+	return rules
+}
+
+// startPosition 内置摆法、开局库或随机撒子
+func startPosition(s *source, rules board.RuleSet) *board.Game {
+	start := int8(s.intn(int(rules.Players)))
+	switch s.intn(3) {
+	case 1:
+		names := board.Variants()
+		if len(names) > 0 {
+			if g, err := board.NewGameFromVariant(names[s.intn(len(names))], start, rules); err == nil {
+				return g
+			}
+		}
+	case 2:
+		return scatter(s, rules, start)
+	}
+	return board.NewGame(start, rules)
+}
+
+// scatter 随机撒子：每方先被推出 0-3 颗（记在随机一个对手名下，不让任何一队达到获胜子数），
+// 余下的棋子随机落在空格上
+func scatter(s *source, rules board.RuleSet, start int8) *board.Game {
+	g := board.NewGame(start, rules)
+	p := readPosition(g)
+	var taken [board.MaxPlayers]int8 // 按队伍统计吃子
+	for pl := int8(0); pl < rules.Players; pl++ {
+		for k := s.intn(4); k > 0; k-- {
+			q := int8(s.intn(int(rules.Players)))
+			for tries := int8(0); tries < rules.Players; tries, q = tries+1, (q+1)%rules.Players {
+				if p.team(q) != p.team(pl) && taken[p.team(q)]+1 < rules.MarblesToWin {
+					taken[p.team(q)]++
+					p.captures[q]++
+					p.damages[pl]++
+					break
+				}
+			}
+		}
+	}
+
+	// 按原数量减去被推出数重新落子
+	var count [board.MaxPlayers]int
+	for _, tok := range p.marbles {
+		count[tok]++
+	}
+	p.marbles = map[hex]int8{}
+	n := int(g.CellCount())
+	for pl := int8(0); pl < rules.Players; pl++ {
+		for k := count[pl] - int(p.damages[pl]); k > 0; k-- {
+			pos := s.intn(n)
+			for p.has(hexOf(g, int8(pos))) {
+				pos = (pos + 1) % n
+			}
+			p.marbles[hexOf(g, int8(pos))] = pl
+		}
+	}
+	if err := g.UnmarshalText([]byte(positionText(g, p))); err != nil {
+		panic(fmt.Sprintf("rulecheck: scatter produced a bad position: %v", err))
+	}
+	return g
+}
+
+// positionText 按 board 的文本局面格式（见 board/position.go）写出参考局面，回合数记 1
+func positionText(g *board.Game, p *refPosition) string {
+	var rows []string
+	var row strings.Builder
+	empty, inRow, rowLen := 0, 0, int(p.side)
+	flush := func() {
+		if empty > 0 {
+			row.WriteString(strconv.Itoa(empty))
+			empty = 0
+		}
+	}
+	for pos := int8(0); pos < g.CellCount(); pos++ {
+		if tok, ok := p.marbles[hexOf(g, pos)]; ok {
+			flush()
+			row.WriteByte('a' + byte(tok))
+		} else {
+			empty++
+		}
+		if inRow++; inRow == rowLen {
+			flush()
+			rows = append(rows, row.String())
+			row.Reset()
+			inRow = 0
+			if len(rows) < int(p.side) {
+				rowLen++
+			} else {
+				rowLen--
+			}
+		}
+	}
+
+	n := p.rules.Players
+	counts := func(c [board.MaxPlayers]int8) string {
+		parts := make([]string, n)
+		for i := range parts {
+			parts[i] = strconv.Itoa(int(c[i]))
+		}
+		return strings.Join(parts, "-")
+	}
+	ejections := counts(p.damages)
+	if n > 2 {
+		ejections += ":" + counts(p.captures)
+	}
+	return fmt.Sprintf("v1 %s %c %s 1", strings.Join(rows, "/"), 'a'+p.toMove, ejections)
+}
@@ -0,0 +1,233 @@
+// File internal/rulecheck/reference.go
+package rulecheck
+
+import "abalone_go/internal/board"
+
+// 参考实现：照规则书逐条写的 Abalone 规则。不用位图、不用预计算表、不追求速度，
+// 只通过 board 的公开接口读局面，用来与 board 的走法生成 / GroupMove / ValidateMove / Apply 对拍。
+//
+//	一步 = 1..MaxLine 颗相连共线的己子 + 六个方向之一
+//	沿棋串方向（单子任意方向）：前方空格则整体前进；前方是对手棋子时按 RuleSet.Pushes 推子，
+//	  对手串后面必须是空格或棋盘外，推到棋盘外的那颗记为吃子
+//	与棋串斜交：侧移，每个目标格都必须是棋盘内的空格
+//	己子 / 队友挡路不能走；领头子走出棋盘只在 SelfEjectLoses 下合法，走的一方立即判负
+
+// hex 轴坐标：x=c-side, z=r-side（立方坐标 y=-x-z）
+type hex struct{ x, z int8 }
+
+// hexDirs 六个方向，顺序与 board.ACTIONS 一致：(dr,dc) → (dx,dz)=(dc,dr)
+var hexDirs = [6]hex{{1, 0}, {0, 1}, {-1, 1}, {-1, 0}, {0, -1}, {1, -1}}
+
+func (h hex) add(d hex) hex { return hex{h.x + d.x, h.z + d.z} }
+
+// refPosition 参考实现眼中的局面
+type refPosition struct {
+	side     int8
+	rules    board.RuleSet
+	marbles  map[hex]int8 // 盘上的棋子
+	toMove   int8
+	damages  [board.MaxPlayers]int8
+	captures [board.MaxPlayers]int8
+}
+
+// readPosition 通过公开接口读出 g 的局面
+func readPosition(g *board.Game) *refPosition {
+	p := &refPosition{side: g.Side(), rules: g.Rules(), marbles: map[hex]int8{}, toMove: g.CurrentPlayer}
+	for pos := int8(0); pos < g.CellCount(); pos++ {
+		if tok := g.TokenAt(pos); tok >= 0 {
+			p.marbles[hexOf(g, pos)] = tok
+		}
+	}
+	for pl := int8(0); pl < p.rules.Players; pl++ {
+		p.damages[pl], p.captures[pl] = g.Damages(pl), g.Captures(pl)
+	}
+	return p
+}
+
+func hexOf(g *board.Game, pos int8) hex {
+	r, c := g.PosToCoord(pos)
+	return hex{c - g.Side(), r - g.Side()}
+}
+
+func posOf(g *board.Game, h hex) int8 { return g.CoordToPos(h.z+g.Side(), h.x+g.Side()) }
+
+// onBoard 立方坐标三个分量都不超过 side-1
+func (p *refPosition) onBoard(h hex) bool {
+	lim := p.side - 1
+	y := -h.x - h.z
+	return h.x >= -lim && h.x <= lim && h.z >= -lim && h.z <= lim && y >= -lim && y <= lim
+}
+
+// team 4 人局 A+C 对 B+D，其余各自为战
+func (p *refPosition) team(pl int8) int8 {
+	if p.rules.Players == 4 {
+		return pl % 2
+	}
+	return pl
+}
+
+func (p *refPosition) canPush(attackers, defenders int) bool {
+	for _, r := range p.rules.Pushes {
+		if int(r.Attackers) == attackers && int(r.Defenders) == defenders {
+			return true
+		}
+	}
+	return false
+}
+
+// refMove 一个候选 (棋串, 方向) 及其判定结果
+type refMove struct {
+	group  []hex // 沿轴正方向排列
+	dir    int8
+	legal  bool
+	kind   board.MoveKind
+	after  *refPosition // 走完之后的局面；非法时为 nil
+	winner int8         // 这一步直接分出胜负时为胜队，否则为 -1
+}
+
+// candidates 枚举所有 (棋串, 方向)，逐个按规则书判定
+func (p *refPosition) candidates() []refMove {
+	var out []refMove
+	for h, tok := range p.marbles {
+		if tok != p.toMove {
+			continue
+		}
+		for axis := int8(0); axis < 3; axis++ {
+			group := []hex{h}
+			for {
+				if len(group) > 1 || axis == 0 { // 单子只枚举一次
+					for dir := int8(0); dir < 6; dir++ {
+						out = append(out, p.judge(append([]hex(nil), group...), axis, dir))
+					}
+				}
+				next := group[len(group)-1].add(hexDirs[axis])
+				if len(group) == int(p.rules.MaxLine) || p.marbles[next] != p.toMove || !p.has(next) {
+					break
+				}
+				group = append(group, next)
+			}
+		}
+	}
+	return out
+}
+
+func (p *refPosition) has(h hex) bool { _, ok := p.marbles[h]; return ok }
+
+// judge 判定棋串 group（沿 axis 正方向排列）朝 dir 走一步
+func (p *refPosition) judge(group []hex, axis, dir int8) refMove {
+	m := refMove{group: group, dir: dir, winner: -1}
+	d := hexDirs[dir]
+	me := p.toMove
+	moving := map[hex]bool{}
+	for _, h := range group {
+		moving[h] = true
+	}
+
+	if len(group) > 1 && dir%3 != axis { // ---- 侧移 ----
+		if !p.rules.Broadside {
+			return m
+		}
+		for _, h := range group {
+			if t := h.add(d); !p.onBoard(t) || p.has(t) {
+				return m
+			}
+		}
+		m.legal, m.kind = true, board.KindSidestepMove
+		m.after = p.shift(group, d, nil)
+		return m
+	}
+
+	// ---- 沿棋串方向：找领头子 ----
+	lead := group[0]
+	for _, h := range group {
+		if !moving[h.add(d)] {
+			lead = h
+		}
+	}
+	front := lead.add(d)
+	switch {
+	case !p.onBoard(front): // 己子走出棋盘
+		if !p.rules.SelfEjectLoses {
+			return m
+		}
+		m.legal, m.kind = true, board.KindSelfEject
+		m.after = p.shift(group, d, &lead)
+		m.winner = p.team((me + 1) % p.rules.Players)
+		return m
+	case !p.has(front):
+		m.legal, m.kind = true, board.KindInlineMove
+		m.after = p.shift(group, d, nil)
+		return m
+	case p.team(p.marbles[front]) == p.team(me): // 己子或队友
+		return m
+	}
+
+	// ---- 推子：数连续的对手棋子（不同对手可以混在一起）----
+	var enemies []hex
+	h := front
+	for p.has(h) && p.team(p.marbles[h]) != p.team(me) {
+		enemies = append(enemies, h)
+		h = h.add(d)
+	}
+	if p.onBoard(h) && p.has(h) { // 对手串后面是己子或队友
+		return m
+	}
+	if !p.canPush(len(group), len(enemies)) {
+		return m
+	}
+	chain := append(append([]hex(nil), group...), enemies...)
+	m.legal, m.kind = true, board.KindInlinePush
+	if p.onBoard(h) {
+		m.after = p.shift(chain, d, nil)
+		return m
+	}
+	last := enemies[len(enemies)-1]
+	m.after = p.shift(chain, d, &last)
+	m.kind = board.KindEjected
+	m.after.captures[me]++
+	var teamCaptures int8
+	for pl := int8(0); pl < p.rules.Players; pl++ {
+		if p.team(pl) == p.team(me) {
+			teamCaptures += m.after.captures[pl]
+		}
+	}
+	if teamCaptures >= p.rules.MarblesToWin {
+		m.kind, m.winner = board.KindWinner, p.team(me)
+	}
+	return m
+}
+
+// shift 返回 cells 全部沿 d 前进一格后的局面；out 非空时那颗被推出棋盘。
+// 行棋方换到下家。
+func (p *refPosition) shift(cells []hex, d hex, out *hex) *refPosition {
+	next := *p
+	next.marbles = make(map[hex]int8, len(p.marbles))
+	for h, tok := range p.marbles {
+		next.marbles[h] = tok
+	}
+	for _, h := range cells {
+		delete(next.marbles, h)
+	}
+	for _, h := range cells {
+		if out != nil && h == *out {
+			next.damages[p.marbles[h]]++
+			continue
+		}
+		next.marbles[h.add(d)] = p.marbles[h]
+	}
+	next.toMove = (p.toMove + 1) % p.rules.Players
+	return &next
+}
+
+// equal 两个局面的棋子、行棋方、被推出数与吃子数是否完全相同
+func (p *refPosition) equal(o *refPosition) bool {
+	if len(p.marbles) != len(o.marbles) || p.toMove != o.toMove || p.damages != o.damages || p.captures != o.captures {
+		return false
+	}
+	for h, tok := range p.marbles {
+		if t, ok := o.marbles[h]; !ok || t != tok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+// File internal/rulecheck/rulecheck_test.go
+package rulecheck_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"abalone_go/internal/rulecheck"
+)
+
+// testGames TestRun 跑的随机对局数；更多的用 `abalone fuzz -n` 或 go test -fuzz=FuzzRun
+const testGames = 10
+
+// TestRun 固定种子生成的随机对局逐步与参考实现对拍，取数方式与 `abalone fuzz` 相同
+func TestRun(t *testing.T) {
+	n := testGames
+	if testing.Short() {
+		n = 2
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < n; i++ {
+		b := make([]byte, 32+rng.Intn(480))
+		rng.Read(b)
+		if _, err := rulecheck.Run(b); err != nil {
+			t.Fatalf("case %d: %v\nreplay: abalone fuzz -data %x", i, err, b)
+		}
+	}
+}
+
+// FuzzRun go test -fuzz=FuzzRun ./internal/rulecheck
+func FuzzRun(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte("abalone"))
+	f.Add([]byte{0, 0, 0, 0, 1, 0, 0, 0})             // 标准规则、内置摆法
+	f.Add([]byte{5, 0, 3, 1, 0, 2, 2, 7, 1, 9, 4, 8}) // 多人局
+	f.Add([]byte{0, 0, 0, 3, 0, 2, 0, 1, 2, 3, 0, 1, 3, 2, 1, 0, 3, 1, 2, 3, 0, 2})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if _, err := rulecheck.Run(data); err != nil {
+			t.Fatalf("%v\nreplay: abalone fuzz -data %x", err, data)
+		}
+	})
+}
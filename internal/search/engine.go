@@ -0,0 +1,355 @@
+// internal/search/engine.go
+package search
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"abalone_go/internal/board"
+	"abalone_go/internal/eval"
+	"abalone_go/internal/tt"
+)
+
+/* ──────────────── 引擎 ──────────────── */
+
+// Options 引擎设置，零值字段取默认
+type Options struct {
+	Threads int         // 搜索线程数（Lazy SMP，共享置换表）；0 为 runtime.NumCPU()-1（至少 1）
+	HashPow uint8       // 置换表 2^HashPow 项；0 为 tt.DefaultPow
+	Limits  Limits      // Think 使用的默认搜索限制
+	Eval    eval.Params // 评估权重；零值为 eval.DefaultParams()
+
+	// Info 搜索进度回调，见 Info；在调用 Search 的 goroutine 里调用，应尽快返回
+	Info func(Info)
+}
+
+// Stats 最近一次搜索的统计
+type Stats struct {
+	Depth    int8          // 最后完成的迭代深度
+	SelDepth int8          // 到达的最深 ply（含静态搜索）
+	Nodes    uint64        // 访问的节点数（含静态搜索；被丢弃的那一轮也计入）
+	Elapsed  time.Duration // 用时
+}
+
+// Engine 一个搜索引擎：自带置换表、设置与统计，多个引擎可在同一进程里互不干扰地对弈。
+// 同一个 Engine 不能并发搜索
+type Engine struct {
+	opts  Options
+	tt    *tt.Table
+	stats Stats
+	start time.Time // 本次搜索开始的时刻
+	live  []*worker // 正在搜索这一轮的 worker，供进度报告累计节点数
+
+	reported reported // 最近一份进度报告
+}
+
+// NewEngine 按 opts 创建引擎并分配置换表
+func NewEngine(opts Options) *Engine {
+	if opts.Threads <= 0 {
+		opts.Threads = runtime.NumCPU() - 1
+		if opts.Threads < 1 {
+			opts.Threads = 1
+		}
+	}
+	if opts.HashPow == 0 {
+		opts.HashPow = tt.DefaultPow
+	}
+	if opts.Eval == (eval.Params{}) {
+		opts.Eval = eval.DefaultParams()
+	}
+	return &Engine{opts: opts, tt: tt.New(opts.HashPow)}
+}
+
+// Options 补全默认值之后的设置
+func (e *Engine) Options() Options { return e.opts }
+
+// SetLimits 修改 Think 使用的默认搜索限制
+func (e *Engine) SetLimits(lim Limits) { e.opts.Limits = lim }
+
+// Stats 最近一次搜索的统计
+func (e *Engine) Stats() Stats { return e.stats }
+
+// Clear 清空置换表，如开始新的一局时
+func (e *Engine) Clear() { e.tt.Clear() }
+
+// Think 按 Options.Limits 搜索，见 Search
+func (e *Engine) Think(ctx context.Context, root *board.Game) (board.Move, int32, bool) {
+	return e.Search(ctx, root, e.opts.Limits)
+}
+
+// Search 按 lim 迭代加深；Limits{Hard: 5 * time.Second} 即“5 秒内尽量深”。
+// ctx 取消与硬限制到时一样：各 worker 在几毫秒内停下，未完成的这一轮丢弃。
+// 返回最后一轮完成的迭代中的最佳着法，分数为行棋方视角；没有合法着法时 ok 为 false。
+// 搜索期间不得改动 root
+func (e *Engine) Search(ctx context.Context, root *board.Game, lim Limits) (best board.Move, score int32, ok bool) {
+	e.start = time.Now()
+	e.stats = Stats{}
+	e.reported = reported{}
+	best, score, ok = e.think(ctx, root, lim)
+	e.stats.Elapsed = time.Since(e.start)
+	return best, score, ok
+}
+
+// worker 一个搜索线程：共享引擎的置换表与权重，节点数各记各的；
+// 每个节点都看一眼 stop，叫停后立即返回
+type worker struct {
+	tt       *tt.Table
+	eval     *eval.Params
+	stop     *cancelToken
+	nodes    atomic.Uint64
+	selDepth atomic.Int32
+}
+
+func (e *Engine) newWorker(stop *cancelToken) *worker {
+	return &worker{tt: e.tt, eval: &e.opts.Eval, stop: stop}
+}
+
+// visit 计一个节点，并记下到达的最深 ply
+func (w *worker) visit(ply int8) {
+	w.nodes.Add(1)
+	if int32(ply) > w.selDepth.Load() {
+		w.selDepth.Store(int32(ply))
+	}
+}
+
+// progress 到目前为止的节点数与最深 ply，含正在进行的这一轮
+func (e *Engine) progress() (nodes uint64, selDepth int8) {
+	nodes, selDepth = e.stats.Nodes, e.stats.SelDepth
+	for _, w := range e.live {
+		nodes += w.nodes.Load()
+		if d := int8(w.selDepth.Load()); d > selDepth {
+			selDepth = d
+		}
+	}
+	return nodes, selDepth
+}
+
+/* ──────────────── 迭代加深与用时 ──────────────── */
+
+// Limits 搜索限制，零值字段按下述规则补全（全为零时一直加深到 MaxDepth）：
+//
+//	Depth  最大深度；0 为不限，由时间决定
+//	Soft   软限制：用时超过后不再开始新一轮迭代；0 取 Hard 的一半
+//	Hard   硬限制：到时立即停止，丢弃未完成的这一轮；0 取 Soft 的两倍
+//
+// 最佳着法连续 StableIters 轮不变时，软限制减半；第一轮总会完成（不受时限与 ctx 影响）。
+type Limits struct {
+	Depth int8
+	Soft  time.Duration
+	Hard  time.Duration
+}
+
+const (
+	MaxDepth    = 64 // 迭代加深的深度上限
+	StableIters = 3  // 最佳着法连续这么多轮不变即视为稳定
+	iterGrowth  = 3  // 估计下一轮用时约为上一轮的倍数，来不及完成就不再开始
+)
+
+func (lim Limits) withDefaults() Limits {
+	if lim.Depth <= 0 || lim.Depth > MaxDepth {
+		lim.Depth = MaxDepth
+	}
+	if lim.Soft == 0 {
+		lim.Soft = lim.Hard / 2
+	}
+	if lim.Hard == 0 {
+		lim.Hard = 2 * lim.Soft
+	}
+	return lim
+}
+
+// think 逐层加深，每轮按上一轮的结果重排根着法（最佳着法在前）；
+// 只采用完整搜完的一轮，被硬限制打断的那一轮整个丢弃
+func (e *Engine) think(ctx context.Context, root *board.Game, lim Limits) (board.Move, int32, bool) {
+	moves := orderMoves(root, board.LegalMoves(root))
+	if len(moves) == 0 {
+		return board.Move{}, 0, false
+	}
+	lim = lim.withDefaults()
+	start := time.Now()
+
+	if lim.Hard > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, lim.Hard)
+		defer cancel()
+	}
+
+	var best result
+	stable := 0
+	for depth := int8(1); depth <= lim.Depth; depth++ {
+		iterStart := time.Now()
+		iterCtx := ctx
+		if depth == 1 {
+			iterCtx = context.WithoutCancel(ctx) // 第一轮必须完成，保证总有一步可走
+		}
+		res, reached, complete := e.searchRoot(iterCtx, root, moves, depth)
+		if !complete {
+			break
+		}
+		depth = reached // 辅助 worker 可能先搜完了更深的一层
+		if len(best.move.Group) > 0 && moveKey(res[0].move) == moveKey(best.move) {
+			stable++
+		} else {
+			stable = 0
+		}
+		best = res[0]
+		e.stats.Depth = depth
+		e.report(root, depth, best)
+		for i, r := range res {
+			moves[i] = r.move
+		}
+
+		// 本轮已算清胜负或只有一步可走，再深也不会改变选择
+		if proven(best.score, depth) || len(moves) == 1 {
+			break
+		}
+		elapsed := time.Since(start)
+		soft := lim.Soft
+		if stable >= StableIters {
+			soft /= 2
+		}
+		if (soft > 0 && elapsed >= soft) || ctx.Err() != nil {
+			break
+		}
+		if lim.Hard > 0 && elapsed+iterGrowth*time.Since(iterStart) > lim.Hard {
+			break
+		}
+	}
+	return best.move, best.score, true
+}
+
+// proven 分数是否为本轮搜索树内实际走到的终局：评估分截断在 eval.MaxScore 以内，
+// 胜负分只来自终局节点，且其步数不超过本轮深度
+func proven(score int32, depth int8) bool {
+	return tt.IsMate(score) && mateValue-abs32(score) <= int32(depth)
+}
+
+/* ──────────────── Lazy SMP ──────────────── */
+
+// searchRoot 一轮 Lazy SMP：Threads 个 worker 各自从根搜整棵树，经置换表共享结果。
+// 主 worker（0 号）按 moves 的顺序搜到 depth 层；辅助 worker 一半搜 depth、一半搜 depth+1，
+// 并轮换除首步以外的根着法顺序，彼此错开。谁先搜完就采用谁的结果（辅助 worker 可能更深），
+// 其余随即叫停。res 按分数从高到低（非最佳着法的分数只是上界），reached 为其深度。
+// 主 worker 的最佳着法在本轮中换人时报一次进度。
+// ctx 结束时返回 complete=false；返回前等所有 worker 退出，之后不会再有人读写置换表。
+// 多人局的 paranoid 不用置换表，辅助 worker 帮不上忙，只用一个 worker
+func (e *Engine) searchRoot(ctx context.Context, root *board.Game, moves []board.Move, depth int8) (
+	res []result, reached int8, complete bool) {
+
+	type finished struct {
+		res   []result
+		depth int8
+	}
+	doneCh := make(chan finished, e.opts.Threads) // 容量足够，worker 不会阻塞
+	bestCh := make(chan result, 16)               // 主 worker 的新最佳；满了就丢
+
+	stop := &cancelToken{} // 本轮专用：ctx 结束或已有结果时叫停所有 worker
+	defer context.AfterFunc(ctx, stop.Abort)()
+	threads := e.opts.Threads
+	if root.Players() > 2 {
+		threads = 1
+	}
+	var wg sync.WaitGroup
+	workers := make([]*worker, threads)
+	e.live = workers
+	defer func() {
+		stop.Abort()
+		wg.Wait()
+		e.stats.Nodes, e.stats.SelDepth = e.progress()
+		e.live = nil
+	}()
+
+	for i := range workers {
+		w := e.newWorker(stop)
+		workers[i] = w
+		local := root.Clone()
+		order, d := moves, depth
+		var onBest func(result)
+		if i == 0 {
+			onBest = func(r result) {
+				select {
+				case bestCh <- r:
+				default:
+				}
+			}
+		} else {
+			d += int8(i % 2)
+			order = rotateTail(moves, i)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if res, ok := w.searchRoot(local, order, d, onBest); ok {
+				doneCh <- finished{res, d}
+			}
+		}()
+	}
+
+	for {
+		select {
+		case f := <-doneCh:
+			return f.res, f.depth, true
+		case r := <-bestCh:
+			e.report(root, depth, r)
+		case <-ctx.Done():
+			return nil, 0, false
+		}
+	}
+}
+
+// rotateTail 首步不动，其余着法循环左移 k 位
+func rotateTail(moves []board.Move, k int) []board.Move {
+	out := append([]board.Move(nil), moves...)
+	if tail := out[1:]; len(tail) > 1 {
+		k %= len(tail)
+		copy(tail, append(append([]board.Move(nil), moves[1+k:]...), moves[1:1+k]...))
+	}
+	return out
+}
+
+// searchRoot 一个 worker 从根搜到 depth 层：首步全窗，其余零窗试探、超过 alpha 再全窗重搜。
+// 分数为行棋方视角（多人局为 paranoid 的 me 视角）；被叫停时 ok 为 false。
+// 根上 alpha 提高时把新的最佳着法交给 onBest（可为 nil）
+func (w *worker) searchRoot(node *board.Game, moves []board.Move, depth int8, onBest func(result)) (
+	res []result, ok bool) {
+
+	w.visit(0)
+	me := node.CurrentPlayer
+	alpha, beta := int32(-mateValue), int32(mateValue)
+	res = make([]result, 0, len(moves))
+	for i, m := range moves {
+		u := node.Make(m)
+		var sc int32
+		switch {
+		case node.Players() > 2:
+			sc = w.paranoid(node, depth-1, alpha, beta, 1, me)
+		case i == 0:
+			sc, _ = w.pvs(node, depth-1, -beta, -alpha, 1, true)
+			sc = -sc
+		default:
+			sc, _ = w.pvs(node, depth-1, -alpha-1, -alpha, 1, false)
+			sc = -sc
+			if sc > alpha {
+				sc, _ = w.pvs(node, depth-1, -beta, -alpha, 1, true)
+				sc = -sc
+			}
+		}
+		node.Unmake(u)
+		if w.stop.IsAborted() {
+			return nil, false // 半途叫停的分数不可信
+		}
+		res = append(res, result{sc, m})
+		if sc > alpha {
+			alpha = sc
+			if i > 0 && onBest != nil {
+				onBest(result{sc, m})
+			}
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].score > res[j].score })
+	return res, true
+}
@@ -0,0 +1,123 @@
+// internal/search/info.go
+package search
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"abalone_go/internal/board"
+	"abalone_go/internal/tt"
+)
+
+/* ──────────────── 搜索信息 ──────────────── */
+
+// Info 一次搜索进度报告：每轮迭代完成时一份；一轮之中根着法的最佳者换人时也报一份
+// （此时 Depth 为正在搜的这一轮）
+type Info struct {
+	Depth    int8          // 迭代深度
+	SelDepth int8          // 实际到达的最深 ply（含静态搜索）
+	Score    int32         // 行棋方视角
+	Mate     int           // >0：N 步（行棋方自己的步数）内推出制胜一子；<0：N 步内告负；0：未分胜负
+	Nodes    uint64        // 本次搜索累计节点数
+	NPS      uint64        // 每秒节点数
+	HashFull int           // 置换表占用，千分比
+	Elapsed  time.Duration // 自搜索开始
+	PV       []string      // 主变例（标准记法），首步为根着法，其余沿置换表中的最佳着法回溯
+}
+
+// ScoreText 分数的文字形式，如 "+35"、"win in 3"、"loss in 2"
+func (i Info) ScoreText() string {
+	switch {
+	case i.Mate > 0:
+		return fmt.Sprintf("win in %d", i.Mate)
+	case i.Mate < 0:
+		return fmt.Sprintf("loss in %d", -i.Mate)
+	}
+	return fmt.Sprintf("%+d", i.Score)
+}
+
+// String 一行文字，供终端与文本协议输出：
+//
+//	depth 5/9 score +35 nodes 123456 nps 81000 hashfull 12.3% time 1.52s pv G3G5F3 C5C7D5 ...
+func (i Info) String() string {
+	return fmt.Sprintf("depth %d/%d score %s nodes %d nps %d hashfull %.1f%% time %s pv %s",
+		i.Depth, i.SelDepth, i.ScoreText(), i.Nodes, i.NPS, float64(i.HashFull)/10,
+		i.Elapsed.Round(10*time.Millisecond), strings.Join(i.PV, " "))
+}
+
+// reported 最近一份报告的要点，用于去重
+type reported struct {
+	depth int8
+	move  uint32
+	score int32
+}
+
+// mateIn 杀棋分折算成行棋方自己的步数：正为胜，负为负
+func mateIn(score int32, players int8) int {
+	if !tt.IsMate(score) {
+		return 0
+	}
+	ply := int(mateValue - abs32(score))
+	n := (ply + int(players) - 1) / int(players)
+	if score < 0 {
+		return -n
+	}
+	return n
+}
+
+// pv 根着法 m 加上沿置换表一路取出的最佳应着；查不到、不合法或局面重复即止
+func (e *Engine) pv(root *board.Game, m board.Move, maxLen int) []string {
+	g := root.Clone()
+	line := []string{g.FormatMove(m)}
+	g.Make(m)
+	seen := map[uint64]bool{root.Hash(): true}
+	for len(line) < maxLen && !g.Result.Over() && !seen[g.Hash()] {
+		seen[g.Hash()] = true
+		entry, ok := e.tt.Lookup(g.Hash())
+		if !ok {
+			break
+		}
+		next, found := board.Move{}, false
+		for _, cand := range board.LegalMoves(g) {
+			if moveKey(cand) == entry.BestMove {
+				next, found = cand, true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+		line = append(line, g.FormatMove(next))
+		g.Make(next)
+	}
+	return line
+}
+
+// report 把 r 作为当前最佳报给 Options.Info；与上一份的深度、着法、分数都相同时不重复报
+func (e *Engine) report(root *board.Game, depth int8, r result) {
+	if e.opts.Info == nil {
+		return
+	}
+	last := reported{depth, moveKey(r.move), r.score}
+	if last == e.reported {
+		return
+	}
+	e.reported = last
+	elapsed := time.Since(e.start)
+	nodes, sel := e.progress()
+	info := Info{
+		Depth:    depth,
+		SelDepth: sel,
+		Score:    r.score,
+		Mate:     mateIn(r.score, root.Players()),
+		Nodes:    nodes,
+		HashFull: e.tt.Fill(),
+		Elapsed:  elapsed,
+		PV:       e.pv(root, r.move, int(depth)),
+	}
+	if elapsed > 0 {
+		info.NPS = uint64(float64(nodes) / elapsed.Seconds())
+	}
+	e.opts.Info(info)
+}
@@ -0,0 +1,52 @@
+// internal/search/paranoid.go
+package search
+
+import (
+	"abalone_go/internal/board"
+)
+
+/* ──────────────── 多人局：Paranoid 搜索 ──────────────── */
+
+// paranoid 3/4 人局的 αβ：假设所有对手联手对付 me，
+// me 与队友的层取最大，对手的层取最小；分数始终是 me 的视角。
+// 同一局面在不同 me 下分数不同，因此不读写 TT。
+func (w *worker) paranoid(node *board.Game, depth int8, alpha, beta int32, ply int8, me int8) int32 {
+	if w.stop.IsAborted() {
+		return 0
+	}
+	w.visit(ply)
+	switch win := node.Result.Winner(); {
+	case node.Result.Outcome == board.Draw || node.Repetitions() > 1:
+		return 0
+	case win != board.TokenEmpty && node.Team(win) == node.Team(me):
+		return mateValue - int32(ply)
+	case win != board.TokenEmpty:
+		return -mateValue + int32(ply)
+	}
+	if depth == 0 {
+		return w.eval.Evaluate(node, me)
+	}
+
+	maximize := !node.IsOpponent(me, node.CurrentPlayer)
+	for _, m := range orderMoves(node, board.LegalMoves(node)) {
+		u := node.Make(m)
+		score := w.paranoid(node, depth-1, alpha, beta, ply+1, me)
+		node.Unmake(u)
+		if w.stop.IsAborted() {
+			return 0
+		}
+		if maximize && score > alpha {
+			alpha = score
+		}
+		if !maximize && score < beta {
+			beta = score
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	if maximize {
+		return alpha
+	}
+	return beta
+}
@@ -3,139 +3,67 @@ package search
 
 import (
 	"math"
-	"runtime"
 	"sort"
-	"time"
 
 	"abalone_go/internal/board"
-	"abalone_go/internal/eval"
 	"abalone_go/internal/tt"
-	"abalone_go/internal/zobrist"
 )
 
-const mateValue = 32000
+const mateValue = tt.MateValue
 
-// —— 共用走法结构 ——
-type mv struct{ from, to int8 }
-
-/* ──────────────── 公开 API ──────────────── */
-
-func BestMove(root *board.Game, depth int8, limit time.Duration) (int8, int8, int32, bool) {
-	return bestCore(root, depth, limit, 1)
-}
-func BestMoveParallel(root *board.Game, depth int8, limit time.Duration) (int8, int8, int32, bool) {
-	w := runtime.NumCPU() - 1
-	if w < 1 {
-		w = 1
-	}
-	return bestCore(root, depth, limit, w)
-}
-
-/* ──────────────── 并行根层 ──────────────── */
-func bestCore(root *board.Game, depth int8, limit time.Duration, workers int) (
-	int8, int8, int32, bool) {
-
-	runtime.GOMAXPROCS(workers + 1)
+/* ──────────────── PVS + NM + LMR + QSearch ──────────────── */
 
-	// ① 准备数据
-	moves := orderMoves(root, genMoves(root))
-	if len(moves) == 0 {
-		return -1, -1, 0, false
+// pvs 被叫停时返回的分数无意义，也不写入 TT；调用方丢弃整轮结果
+func (w *worker) pvs(node *board.Game, depth int8, alpha, beta int32, ply int8, isPV bool) (int32, uint32) {
+	if w.stop.IsAborted() {
+		return 0, 0
 	}
+	w.visit(ply)
 
-	taskCh := make(chan mv, len(moves))
-	resCh := make(chan result, len(moves))
-	cancel := &cancelToken{} // 前文实现：IsAborted / Abort
-
-	// ② 启动 worker
-	for w := 0; w < workers; w++ {
-		go func() {
-			for m := range taskCh {
-				if cancel.IsAborted() {
-					return
-				} // ① 直接退出
-				child := *root
-				if ok, _, mods := child.ValidateMove(m.from, m.to); ok {
-					child.Apply(mods)
-					h := zobrist.HashFromCells(flatCells(&child))
-					sc, _ := pvs(&child, h, depth-1, -mateValue, mateValue, 1, false)
-					if cancel.IsAborted() {
-						return
-					} // ② 计算完再检查一次
-					select { // ③ 如果主协程在读不到，就丢弃
-					case resCh <- result{-sc, m.from, m.to}:
-					default: // resCh 已没人读
-					}
-				}
-			}
-		}()
+	/* --- 和棋：终局和棋，或搜索路径上重复出现的局面 --- */
+	if node.Result.Outcome == board.Draw || node.Repetitions() > 1 {
+		return 0, 0
 	}
-
-	// ③ 投递所有任务 **并立即关闭** —— 只关这一次
-	for _, m := range moves {
-		taskCh <- m
-	}
-	close(taskCh)
-
-	// ④ 主循环收结果 / 超时
-	best := result{score: math.MinInt32}
-	timeout := time.After(limit)
-
-	for done := 0; done < len(moves); done++ {
-		select {
-		case r := <-resCh:
-			if r.score > best.score {
-				best = r
-			}
-			if abs32(r.score) > mateValue-500 {
-				cancel.Abort() // 提前通知 worker 退出
-				return best.from, best.to, best.score, true
-			}
-		case <-timeout:
-			cancel.Abort()
-			return best.from, best.to, best.score, true
+	/* --- 已分胜负（含走出己子判负）：按步数折算的杀棋分 --- */
+	if win := node.Result.Winner(); win != board.TokenEmpty {
+		if win == node.CurrentPlayer {
+			return mateValue - int32(ply), 0
 		}
+		return -mateValue + int32(ply), 0
 	}
-	// 正常结束
-	return best.from, best.to, best.score, true
-}
-
-/* ──────────────── PVS + NM + LMR + QSearch ──────────────── */
 
-func pvs(node *board.Game, hash uint64, depth int8, alpha, beta int32, ply int8, isPV bool) (int32, uint32) {
 	/* --- Quiescence --- */
-	if depth == 0 || node.GameOver {
-		return quiesce(node, alpha, beta, ply), 0
+	if depth == 0 || node.Result.Over() {
+		return w.quiesce(node, alpha, beta, ply), 0
 	}
 
 	/* --- Null-Move (禁止在 PV) --- */
 	if !isPV && depth >= 3 {
-		null := *node
-		null.CurrentPlayer ^= 1 // 让一手
-		score, _ := pvs(&null, hash^0xABCDEF, depth-3, -beta, -beta+1, ply+1, false)
+		u := node.MakeNull() // 让一手
+		score, _ := w.pvs(node, depth-3, -beta, -beta+1, ply+1, false)
+		node.Unmake(u)
+		if w.stop.IsAborted() {
+			return 0, 0
+		}
 		if -score >= beta {
 			return beta, 0
 		}
 	}
 
 	/* --- TT Probe --- */
-	if s, mv, ok := ttProbe(hash, depth, alpha, beta, ply); ok {
+	hash := node.Hash()
+	if s, mv, ok := w.ttProbe(hash, depth, alpha, beta, ply); ok {
 		return s, mv
 	}
 
+	alphaOrig := alpha // 存 TT 时据此区分精确值与上界
 	bestScore := int32(math.MinInt32)
 	var bestMove uint32
 	moveCount := 0
 
-	for _, m := range orderMoves(node, genMoves(node)) {
+	for _, m := range orderMoves(node, board.LegalMoves(node)) {
 		moveCount++
-		child := *node
-		ok, _, mods := child.ValidateMove(m.from, m.to)
-		if !ok {
-			continue
-		}
-		child.Apply(mods)
-		newHash := zobrist.HashFromCells(flatCells(&child))
+		u := node.Make(m)
 
 		/* --- LMR: 后继第4手起、非PV、深度≥3 减 1 --- */
 		reduce := int8(0)
@@ -145,24 +73,28 @@ func pvs(node *board.Game, hash uint64, depth int8, alpha, beta int32, ply int8,
 
 		var score int32
 		if moveCount == 1 { // 首子用全窗
-			score, _ = pvs(&child, newHash, depth-1, -beta, -alpha, ply+1, true)
+			score, _ = w.pvs(node, depth-1, -beta, -alpha, ply+1, true)
 			score = -score
 		} else {
 			// 先零窗
-			score, _ = pvs(&child, newHash, depth-1-reduce, -alpha-1, -alpha, ply+1, false)
+			score, _ = w.pvs(node, depth-1-reduce, -alpha-1, -alpha, ply+1, false)
 			score = -score
 			if score > alpha && reduce > 0 { // LMR 提升
-				score, _ = pvs(&child, newHash, depth-1, -alpha-1, -alpha, ply+1, false)
+				score, _ = w.pvs(node, depth-1, -alpha-1, -alpha, ply+1, false)
 				score = -score
 			}
 			if score > alpha && score < beta { // 窄窗失败高，再全窗
-				score, _ = pvs(&child, newHash, depth-1, -beta, -alpha, ply+1, true)
+				score, _ = w.pvs(node, depth-1, -beta, -alpha, ply+1, true)
 				score = -score
 			}
 		}
+		node.Unmake(u)
+		if w.stop.IsAborted() {
+			return 0, 0
+		}
 
 		if score > bestScore {
-			bestScore, bestMove = score, uint32(m.from)<<8|uint32(m.to)
+			bestScore, bestMove = score, moveKey(m)
 		}
 		if score > alpha {
 			alpha = score
@@ -173,14 +105,18 @@ func pvs(node *board.Game, hash uint64, depth int8, alpha, beta int32, ply int8,
 	}
 
 	/* --- TT Store --- */
-	ttStore(hash, depth, bestScore, alphaOrig(alpha), beta, bestMove, ply)
+	w.ttStore(hash, depth, bestScore, alphaOrig, beta, bestMove, ply)
 
 	return bestScore, bestMove
 }
 
 /* ----- Quiescence: 只扩展 inline_push ----- */
-func quiesce(node *board.Game, alpha, beta int32, ply int8) int32 {
-	stand := eval.Evaluate(node, node.CurrentPlayer)
+func (w *worker) quiesce(node *board.Game, alpha, beta int32, ply int8) int32 {
+	if w.stop.IsAborted() {
+		return 0
+	}
+	w.visit(ply)
+	stand := w.eval.Evaluate(node, node.CurrentPlayer)
 	if stand >= beta {
 		return beta
 	}
@@ -188,17 +124,13 @@ func quiesce(node *board.Game, alpha, beta int32, ply int8) int32 {
 		alpha = stand
 	}
 
-	for _, m := range genMoves(node) {
-		if moveType(node, m.from, m.to) != "inline_push" {
-			continue
-		}
-		child := *node
-		ok, _, mods := child.ValidateMove(m.from, m.to)
-		if !ok {
+	for _, m := range board.LegalMoves(node) {
+		if m.Kind != board.KindInlinePush {
 			continue
 		}
-		child.Apply(mods)
-		score := -quiesce(&child, -beta, -alpha, ply+1)
+		u := node.Make(m)
+		score := -w.quiesce(node, -beta, -alpha, ply+1)
+		node.Unmake(u)
 		if score >= beta {
 			return beta
 		}
@@ -211,8 +143,8 @@ func quiesce(node *board.Game, alpha, beta int32, ply int8) int32 {
 
 /* ----- TT helpers ----- */
 
-func ttProbe(hash uint64, depth int8, alpha, beta int32, ply int8) (int32, uint32, bool) {
-	hit, v, flag, mv := tt.Probe(hash, depth, alpha, beta)
+func (w *worker) ttProbe(hash uint64, depth int8, alpha, beta int32, ply int8) (int32, uint32, bool) {
+	hit, v, flag, mv := w.tt.Probe(hash, depth, alpha, beta)
 	if !hit {
 		return 0, 0, false
 	}
@@ -235,7 +167,7 @@ func ttProbe(hash uint64, depth int8, alpha, beta int32, ply int8) (int32, uint3
 	return 0, 0, false
 }
 
-func ttStore(hash uint64, depth int8, score, alpha, beta int32, mv uint32, ply int8) {
+func (w *worker) ttStore(hash uint64, depth int8, score, alpha, beta int32, mv uint32, ply int8) {
 	flag := tt.Exact
 	if score <= alpha {
 		flag = tt.Upper
@@ -243,36 +175,20 @@ func ttStore(hash uint64, depth int8, score, alpha, beta int32, mv uint32, ply i
 		flag = tt.Lower
 	}
 	val := tt.ToTTScore(score, int32(ply))
-	tt.Store(hash, depth, val, flag, mv)
+	w.tt.Store(hash, depth, val, flag, mv)
 }
 
-func alphaOrig(a int32) int32 { return a } // 留做可读替身
-
 /* ──────────────── 工具 & 排序 ──────────────── */
 
-func genMoves(g *board.Game) []mv {
-	out := make([]mv, 0, 128)
-	for f := int8(0); f < board.N; f++ {
-		for t := int8(0); t < board.N; t++ {
-			if ok, _, _ := g.ValidateMove(f, t); ok {
-				out = append(out, mv{f, t})
-			}
-		}
-	}
-	return out
-}
-
-func flatCells(g *board.Game) []int8 {
-	arr := make([]int8, board.N)
-	for i := int8(0); i < board.N; i++ {
-		arr[i] = g.TokenAt(i)
-	}
-	return arr
+// moveKey 把走法压成 TT 里存的 17 位：首子<<10 | 末子<<3 | 方向（格子 < 128，方向 < 8）
+func moveKey(m board.Move) uint32 {
+	first, last := m.Group[0], m.Group[len(m.Group)-1]
+	return uint32(first)<<10 | uint32(last)<<3 | uint32(m.Dir)
 }
 
 type result struct {
-	score    int32
-	from, to int8
+	score int32
+	move  board.Move
 }
 
 func max32(a, b int32) int32 {
@@ -290,31 +206,21 @@ func abs32(x int32) int32 {
 
 /* ---------- 排序 (推子>三连>侧移>普通) ---------- */
 
-func moveType(g *board.Game, from, to int8) string {
-	if ok, mt, _ := g.ValidateMove(from, to); ok {
-		return mt
-	}
-	return ""
-}
-
-func makesLine3(g *board.Game, m mv) bool {
-	tmp := *g
-	if ok, _, mods := tmp.ValidateMove(m.from, m.to); ok {
-		tmp.Apply(mods)
-	} else {
-		return false
-	}
-	for p := int8(0); p < board.N; p++ {
-		if tmp.TokenAt(p) != g.CurrentPlayer {
+func makesLine3(g *board.Game, m board.Move) bool {
+	me := g.CurrentPlayer
+	u := g.Make(m)
+	defer g.Unmake(u)
+	for p := int8(0); p < g.CellCount(); p++ {
+		if g.TokenAt(p) != me {
 			continue
 		}
-		r, c := tmp.PosToCoord(p)
+		r, c := g.PosToCoord(p)
 		for dir, d := range board.ACTIONS[:3] { // 3 方向即可
-			p1 := safePos(&tmp, r+d[0], c+d[1])
-			p2 := safePos(&tmp, r+2*d[0], c+2*d[1])
+			p1 := g.CoordToPos(r+d[0], c+d[1])
+			p2 := g.CoordToPos(r+2*d[0], c+2*d[1])
 			if p1 >= 0 && p2 >= 0 &&
-				tmp.TokenAt(p1) == g.CurrentPlayer &&
-				tmp.TokenAt(p2) == g.CurrentPlayer {
+				g.TokenAt(p1) == me &&
+				g.TokenAt(p2) == me {
 				return true
 			}
 			if dir >= 2 {
@@ -324,27 +230,20 @@ func makesLine3(g *board.Game, m mv) bool {
 	}
 	return false
 }
-func safePos(g *board.Game, r, c int8) int8 {
-	if r < 0 || r > 10 || c < 0 || c > 10 {
-		return -1
-	}
-	return g.CoordToPos(r, c)
-}
-
-func orderMoves(g *board.Game, list []mv) []mv {
+func orderMoves(g *board.Game, list []board.Move) []board.Move {
 	type s struct {
-		mv mv
+		mv board.Move
 		sc int
 	}
 	buf := make([]s, 0, len(list))
 	for _, m := range list {
 		score := 0
-		switch moveType(g, m.from, m.to) {
-		case "inline_push":
+		switch m.Kind {
+		case board.KindInlinePush:
 			score = 5000
-		case "sidestep_move":
+		case board.KindSidestepMove:
 			score = 3000
-		case "inline_move":
+		case board.KindInlineMove:
 			score = 1000
 		}
 		if makesLine3(g, m) {
@@ -353,7 +252,7 @@ func orderMoves(g *board.Game, list []mv) []mv {
 		buf = append(buf, s{m, score})
 	}
 	sort.Slice(buf, func(i, j int) bool { return buf[i].sc > buf[j].sc })
-	out := make([]mv, len(buf))
+	out := make([]board.Move, len(buf))
 	for i, v := range buf {
 		out[i] = v.mv
 	}
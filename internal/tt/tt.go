@@ -1,6 +1,6 @@
 package tt
 
-import "math"
+import "sync/atomic"
 
 /* ————————— 条目 ————————— */
 
@@ -13,89 +13,170 @@ const (
 )
 
 type Entry struct {
-	Hash     uint64 // 必须排在最前，保证 8 字节对齐
+	Hash     uint64
 	Depth    int8
 	Score    int32
 	Flag     Flag
-	BestMove uint32
+	BestMove uint32 // 只保留低 MoveBits 位
+}
+
+const MoveBits = 22
+
+// pack 把条目内容压进一个 uint64：Score 32 | Depth 8 | Flag 2 | BestMove 22
+func pack(depth int8, score int32, flag Flag, best uint32) uint64 {
+	return uint64(uint32(score))<<32 | uint64(uint8(depth))<<24 |
+		uint64(flag&3)<<MoveBits | uint64(best&(1<<MoveBits-1))
+}
+
+func unpack(hash, data uint64) Entry {
+	return Entry{
+		Hash:     hash,
+		Score:    int32(uint32(data >> 32)),
+		Depth:    int8(uint8(data >> 24)),
+		Flag:     Flag(data>>MoveBits) & 3,
+		BestMove: uint32(data & (1<<MoveBits - 1)),
+	}
 }
 
 /* ————————— 参数 ————————— */
 
-const defaultPow = 22 // 4 MiB: 2^22 × 24 B ≈ 100 Mi
+const DefaultPow = 22 // 2^22 项 × 16 B = 64 MiB
 
-var (
-	table     []Entry
-	sizeMask  uint64
-	emptyHash uint64 = 0
-)
+var emptyHash uint64 = 0
 
-func init() { Resize(defaultPow) }
+// slot 一个表项：key 存 hash^data。两个字各自原子读写，
+// 并发写入交错时 key^data 对不上 hash，读侧当作未命中（lockless hashing）
+type slot struct {
+	key  atomic.Uint64
+	data atomic.Uint64
+}
+
+// Table 置换表；每个搜索引擎各持一张，引擎内的多个搜索线程共享，可并发读写
+type Table struct {
+	slots    []slot
+	sizeMask uint64
+}
 
-func Resize(pow uint8) {
+// New 创建 2^pow 项的置换表；pow 为 0 时取 DefaultPow
+func New(pow uint8) *Table {
+	t := &Table{}
+	t.Resize(pow)
+	return t
+}
+
+// Resize 重新分配为 2^pow 项（清空原有内容）；pow 为 0 时取 DefaultPow
+func (t *Table) Resize(pow uint8) {
+	if pow == 0 {
+		pow = DefaultPow
+	}
 	n := 1 << pow
-	table = make([]Entry, n)
-	sizeMask = uint64(n - 1)
+	t.slots = make([]slot, n)
+	t.sizeMask = uint64(n - 1)
+}
+
+// Len 表项数
+func (t *Table) Len() int { return len(t.slots) }
+
+// Fill 占用率（千分比），按表头至多 1000 项抽样估计
+func (t *Table) Fill() int {
+	n := len(t.slots)
+	if n > 1000 {
+		n = 1000
+	}
+	used := 0
+	for i := range t.slots[:n] {
+		if t.slots[i].key.Load() != emptyHash {
+			used++
+		}
+	}
+	return used * 1000 / n
 }
 
-func Clear() {
-	for i := range table {
-		table[i].Hash = emptyHash
+// Clear 清空；不要与搜索同时进行
+func (t *Table) Clear() {
+	for i := range t.slots {
+		t.slots[i].key.Store(emptyHash)
+		t.slots[i].data.Store(0)
 	}
 }
 
 /* ————————— 无锁 API ————————— */
 
-// Probe：无锁读；读到脏数据会被 Hash 校验挡下
-func Probe(hash uint64, depth int8, alpha, beta int32) (bool, int32, Flag, uint32) {
-	e := &table[hash&sizeMask]
+// load 读出 hash 对应的表项；槽位被别的局面占用或读到写了一半的数据时 ok 为 false
+func (t *Table) load(hash uint64) (Entry, bool) {
+	s := &t.slots[hash&t.sizeMask]
+	data := s.data.Load()
+	key := s.key.Load()
+	if key^data != hash || key == emptyHash {
+		return Entry{}, false
+	}
+	return unpack(hash, data), true
+}
 
-	if e.Hash == hash && e.Depth >= depth {
+// Probe：无锁读；深度不够视为未命中
+func (t *Table) Probe(hash uint64, depth int8, alpha, beta int32) (bool, int32, Flag, uint32) {
+	if hash == emptyHash {
+		hash = 1
+	}
+	e, ok := t.load(hash)
+	if ok && e.Depth >= depth {
 		return true, e.Score, e.Flag, e.BestMove
 	}
 	return false, 0, Exact, 0
 }
 
-// Store：无锁写；直接覆盖
-func Store(hash uint64, depth int8, score int32, flag Flag, best uint32) {
-	e := &table[hash&sizeMask]
+// Lookup：不论深度取出 hash 的表项，用于沿置换表回溯主变例
+func (t *Table) Lookup(hash uint64) (Entry, bool) {
+	if hash == emptyHash {
+		hash = 1
+	}
+	return t.load(hash)
+}
 
+// Store：无锁写；空槽或更深就覆盖
+func (t *Table) Store(hash uint64, depth int8, score int32, flag Flag, best uint32) {
 	// 避免 Hash 为 0（视为空）
 	if hash == emptyHash {
 		hash = 1
 	}
+	s := &t.slots[hash&t.sizeMask]
 
-	// 简单替换策略：空槽或更深就覆盖
-	if e.Hash == emptyHash || depth >= e.Depth {
-		e.Hash = hash // ① 先写 Hash
-		e.Score = score
-		e.Flag = flag
-		e.BestMove = best
-		e.Depth = depth // ② 最后写 Depth（读侧先看 Depth）
+	old := s.data.Load()
+	oldKey := s.key.Load()
+	if oldKey != emptyHash && depth < int8(uint8(old>>24)) {
+		return // 已有更深的结果，保留
 	}
+	data := pack(depth, score, flag, best)
+	s.data.Store(data)
+	s.key.Store(hash ^ data)
 }
 
 /* ————————— Mate ↔ Score ————————— */
 
+// MateValue 在第 ply 层分出胜负的局面记为 ±(MateValue - ply)；评估分必须远小于它。
+// |score| > MateValue-MateBuffer 的都当作胜负分，存表时换算成相对当前节点的步数
 const (
-	mateValue  = math.MaxInt32 / 2
-	mateBuffer = 10000
+	MateValue  = 32000
+	MateBuffer = 500
 )
 
+// IsMate 是否为胜负分
+func IsMate(s int32) bool { return s > MateValue-MateBuffer || s < -MateValue+MateBuffer }
+
 func ToTTScore(s, ply int32) int32 {
-	if s > mateValue-mateBuffer {
+	if s > MateValue-MateBuffer {
 		return s + ply
 	}
-	if s < -mateValue+mateBuffer {
+	if s < -MateValue+MateBuffer {
 		return s - ply
 	}
 	return s
 }
 func FromTTScore(s, ply int32) int32 {
-	if s > mateValue-mateBuffer {
+	if s > MateValue-MateBuffer {
 		return s - ply
 	}
-	if s < -mateValue+mateBuffer {
+	if s < -MateValue+MateBuffer {
 		return s + ply
 	}
 	return s
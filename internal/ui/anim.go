@@ -3,6 +3,7 @@ package ui
 
 import (
 	"abalone_go/internal/board"
+	"log"
 	"math"
 	"time"
 )
@@ -19,6 +20,17 @@ type pieceAnim struct {
 	slotIdx  int8 // 如果被推出，落到右侧三角的第几个格 (0‥5)，否则 -1
 }
 
+// endXY 终点像素坐标；推出的 C/D 棋子或托盘已满时没有槽位，留在原处随动画结束消失
+func (a *pieceAnim) endXY() (float64, float64) {
+	if a.to >= 0 { // 普通落子
+		return marbleXY(cellCenters[a.to])
+	}
+	if int(a.piece) >= len(outCoords) || a.slotIdx < 0 || int(a.slotIdx) >= len(outCoords[a.piece]) {
+		return marbleXY(cellCenters[a.from])
+	}
+	return marbleXY(outCoords[a.piece][a.slotIdx]) // 推子：用 slotIdx 做索引
+}
+
 // screenXY 直接在开始/结束像素坐标间线性插值
 func (a *pieceAnim) screenXY(startXY, endXY func() (float64, float64)) (x, y float64, done bool) {
 	elapsed := time.Since(a.start)
@@ -33,13 +45,18 @@ func (a *pieceAnim) screenXY(startXY, endXY func() (float64, float64)) (x, y flo
 	return x0 + (x1-x0)*p, y0 + (y1-y0)*p, false
 }
 
-func (gl *GameLoop) startAnimations(mods []board.Modification) {
+func (gl *GameLoop) startAnimations(m board.Move) {
+	mods := m.Mods
+	log.Println(moveLabel(gl.logic, m))
 	leavePerf()
 	// 1️⃣ 先让 renderer 记分，这时棋子仍在 OldPos 上
 	gl.rend.applyModifications(mods, gl.logic) // outCounts 正确递增
 
 	// 2️⃣ 计算每颗被推出棋子的槽位 （0‥5），保持与 outCounts 一致
-	nextSlot := [2]int8{int8(outCounts[0] - 1), int8(outCounts[1] - 1)}
+	var nextSlot [board.MaxPlayers]int8
+	for p, n := range outCounts {
+		nextSlot[p] = int8(n - 1)
+	}
 	gl.animating = make([]*pieceAnim, len(mods))
 
 	for i, m := range mods {
@@ -61,8 +78,14 @@ func (gl *GameLoop) startAnimations(mods []board.Modification) {
 		}
 	}
 
-	// 3️⃣ 最后再真正修改棋盘
-	gl.logic.Apply(mods)
+	// 3️⃣ 最后再真正修改棋盘（压入历史栈，支持悔棋）
+	if gl.rec != nil {
+		gl.rec.Add(gl.logic, m)
+	}
+	gl.logic.Play(m)
+	if gl.logic.Result.Over() {
+		gl.saveRecord()
+	}
 
 	// 4️⃣ 锁输入
 	gl.lockInput = true
@@ -1 +1,36 @@
 package ui
+
+import (
+	"fmt"
+
+	"abalone_go/internal/board"
+)
+
+// posToXY 把格子索引 pos 转为屏幕像素坐标
+func posToXY(pos int8) (float64, float64) {
+	cx := cellCenters[pos][0]
+	cy := cellCenters[pos][1]
+	return float64(cx), float64(cy)
+}
+
+/* ---------- 像素坐标 -> 格子索引 ---------- */
+
+func pixelToPos(x, y int) int8 {
+	best := int8(-1)
+	half := int(marbleHalf * cellScale)
+	bestDist := half * half
+	for p := int8(0); p < int8(len(cellCenters)); p++ {
+		dx := x - cellCenters[p][0]
+		dy := y - cellCenters[p][1]
+		d2 := dx*dx + dy*dy
+		if d2 < bestDist {
+			bestDist, best = d2, p
+		}
+	}
+	return best
+}
+
+// moveLabel 日志用：回合号 + 玩家 + 标准记法，如 "12. B A1B2"
+func moveLabel(g *board.Game, m board.Move) string {
+	return fmt.Sprintf("%d. %c %s", g.TurnCount, 'A'+g.CurrentPlayer, g.FormatMove(m))
+}
@@ -3,9 +3,15 @@ package ui
 
 import (
 	"abalone_go/internal/board"
+	"abalone_go/internal/match"
+	"abalone_go/internal/record"
 	"abalone_go/internal/search"
+	"context"
+	"fmt"
 	"github.com/hajimehoshi/ebiten/v2"
 	"log"
+	"os"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,40 +27,160 @@ type GameLoop struct {
 	input  *inputHandler
 	header *headerUI
 
-	pve         bool // true=pve, false=pvp
-	searchDepth int8
-	humanSide   int8 // 仅 pve 有用
+	pve       bool                   // true=pve, false=pvp
+	engine    *search.Engine         // AI；多人局里各 AI 方共用
+	thinking  *aiSearch              // 正在后台进行的搜索；nil 表示没有
+	analysis  atomic.Pointer[string] // AI 最近一次的搜索信息，由搜索 goroutine 写入
+	humanSide int8                   // 仅 pve 有用
 
 	animating []*pieceAnim
 	lockInput bool
-}
 
-// posToXY 把格子索引 pos 转为屏幕像素坐标
-func posToXY(pos int8) (float64, float64) {
-	cx := cellCenters[pos][0]
-	cy := cellCenters[pos][1]
-	return float64(cx), float64(cy)
+	rec     *record.Game // 棋谱；nil 表示不记录
+	recPath string
+	match   *match.Match // 多局比赛；nil 表示单局
 }
 
 func NewGameLoop(g *board.Game, pve bool, depth int8) *GameLoop {
-	return &GameLoop{
+	gl := &GameLoop{
 		logic: g,
 		rend:  newRenderer(),
 		input: &inputHandler{
-			selPos:    -1,
 			pvp:       !pve,          // pvp = 非 pve
 			humanSide: board.PlayerA, // 白方为人（仅 PvE 用）
 		},
-		header:      newHeaderUI(),
-		pve:         pve,
-		searchDepth: depth,
-		humanSide:   board.PlayerA,
+		header:    newHeaderUI(),
+		pve:       pve,
+		humanSide: board.PlayerA,
+	}
+	gl.engine = search.NewEngine(search.Options{
+		Limits: search.Limits{Depth: depth, Hard: 15 * time.Second},
+		Info: func(i search.Info) {
+			line := "AI | " + i.String()
+			gl.analysis.Store(&line)
+		},
+	})
+	layoutCells(g)
+	gl.rend.syncOutCounts(g) // 从局面/棋谱开局时可能已有被推出的子
+	return gl
+}
+
+// SetMoveTime AI 每步的用时上限；搜索迭代加深，到时采用最后完成的一轮
+func (gl *GameLoop) SetMoveTime(d time.Duration) {
+	lim := gl.engine.Options().Limits
+	lim.Hard = d
+	gl.engine.SetLimits(lim)
+}
+
+// RecordTo 记录棋谱，退出或终局时写入 path
+func (gl *GameLoop) RecordTo(path string, rec *record.Game) {
+	gl.rec, gl.recPath = rec, path
+}
+
+// PlayMatch 按比赛进行：pve 下人类是第 0 位参赛方，每局换边；
+// 一局结束后按 N 开始下一局。棋谱（每局一份）写入 RecordTo 指定的文件
+func (gl *GameLoop) PlayMatch(m *match.Match) {
+	gl.match = m
+	gl.logic = m.Game()
+	gl.rec = m.Record()
+	gl.setHumanSide(m.Seat(0))
+}
+
+func (gl *GameLoop) setHumanSide(side int8) {
+	gl.humanSide = side
+	gl.input.humanSide = side
+}
+
+// aiSearch 后台搜索：cancel 叫停（立即采用最后完成的一轮），结果从 done 取
+type aiSearch struct {
+	cancel context.CancelFunc
+	done   chan aiResult
+}
+
+type aiResult struct {
+	move board.Move
+	ok   bool
+}
+
+// startThinking 在后台为当前局面搜索；搜的是副本，界面可以照常重绘
+func (gl *GameLoop) startThinking() {
+	ctx, cancel := context.WithCancel(context.Background())
+	ai := &aiSearch{cancel: cancel, done: make(chan aiResult, 1)}
+	pos := gl.logic.Clone()
+	go func() {
+		best, _, ok := gl.engine.Think(ctx, pos)
+		ai.done <- aiResult{best, ok}
+	}()
+	gl.thinking = ai
+}
+
+// stopThinking 叫停并丢弃后台搜索（悔棋、换局、退出时）
+func (gl *GameLoop) stopThinking() {
+	if gl.thinking == nil {
+		return
 	}
+	gl.thinking.cancel()
+	<-gl.thinking.done
+	gl.thinking = nil
+}
+
+// nextGame 结算比赛中刚结束的这局并开始下一局
+func (gl *GameLoop) nextGame() {
+	if !gl.match.Next() {
+		gl.saveRecord() // 比赛结束：写入最终结果
+		return
+	}
+	gl.rec = gl.match.Record()
+	gl.setHumanSide(gl.match.Seat(0))
+	gl.input.sel, gl.input.msg = nil, ""
+	gl.analysis.Store(nil)
+	gl.rend.syncOutCounts(gl.logic)
+	log.Printf("match: %s, game %d", gl.match, gl.match.Round())
+}
+
+func (gl *GameLoop) saveRecord() {
+	if gl.rec == nil || gl.recPath == "" {
+		return
+	}
+	gl.rec.SetResultFrom(gl.logic)
+	records := []*record.Game{gl.rec}
+	if gl.match != nil {
+		records = gl.match.Records()
+	}
+	f, err := os.Create(gl.recPath)
+	if err != nil {
+		log.Printf("save record: %v", err)
+		return
+	}
+	defer f.Close()
+	wr := record.NewWriter(f)
+	for _, rec := range records {
+		if err := wr.Write(rec); err != nil {
+			log.Printf("save record: %v", err)
+			return
+		}
+	}
+}
+
+// matchStatus header 第二行的比赛进度；单局时为空
+func (gl *GameLoop) matchStatus() string {
+	m := gl.match
+	switch {
+	case m == nil:
+		return ""
+	case m.Over():
+		return fmt.Sprintf("Match over | %s", m)
+	case gl.logic.Result.Over():
+		return fmt.Sprintf("Game %d | %s | press N for the next game", m.Round(), m)
+	}
+	return fmt.Sprintf("Game %d | %s", m.Round(), m)
 }
 
 func (gl *GameLoop) Update() error {
 	// ① Esc 退出
 	if ebiten.IsKeyPressed(ebiten.KeyEscape) {
+		gl.stopThinking()
+		gl.saveRecord()
 		return ebiten.Termination
 	}
 
@@ -65,19 +191,9 @@ func (gl *GameLoop) Update() error {
 		allDone := true
 		for _, a := range gl.animating {
 			startXY := func() (float64, float64) {
-				c := cellCenters[int(a.from)]
-				return float64(c[0] - 24), float64(c[1] - 24)
-			}
-			endXY := func() (float64, float64) {
-				if a.to >= 0 {
-					c := cellCenters[int(a.to)]
-					return float64(c[0] - 24), float64(c[1] - 24)
-				}
-				idx := a.slotIdx
-				oc := outCoords[int(a.piece)][idx]
-				return float64(oc[0] - 24), float64(oc[1] - 24)
+				return marbleXY(cellCenters[a.from])
 			}
-			_, _, done := a.screenXY(startXY, endXY)
+			_, _, done := a.screenXY(startXY, a.endXY)
 			if !done {
 				allDone = false
 			}
@@ -92,31 +208,73 @@ func (gl *GameLoop) Update() error {
 		return nil
 	}
 
-	// ③ AI 回合：这时通常不需要高帧率（省电即可）
-	if gl.pve && gl.logic.CurrentPlayer == board.PlayerB && !gl.logic.GameOver {
-		// （注意：最好不要在 Update 里做长时间阻塞搜索，建议用 goroutine + 标志位。
-		// 但若你现在就是同步搜索，也不必切离省电。）
-		best0, best1, _, _ := search.BestMoveParallel(gl.logic, gl.searchDepth, 15*time.Second)
-		if ok, _, mods := gl.logic.ValidateMove(best0, best1); ok {
-			gl.startAnimations(mods)
-		}
+	if gl.input.copyPressed() {
+		log.Printf("position: %s", gl.logic)
+	}
 
+	if gl.match != nil && gl.logic.Result.Over() && gl.input.nextPressed() {
+		gl.nextGame()
 		return nil
 	}
 
-	// ④ 玩家输入：省电状态下也能响应；一旦要播动画再切全速
-	if mods := gl.input.handleMouse(gl.logic, gl.lockInput); mods != nil {
+	// ③ 悔棋：PvE 一直退回到人类回合
+	if gl.input.undoPressed(gl.lockInput) {
+		gl.takeBack()
+		return nil
+	}
 
-		gl.startAnimations(mods)
+	// ④ AI 回合：后台搜索，这时通常不需要高帧率（省电即可）
+	// 多人局里除人类以外的各方都由 AI 走；空格让 AI 立即出手
+	if gl.pve && gl.logic.CurrentPlayer != gl.humanSide && !gl.logic.Result.Over() {
+		if gl.thinking == nil {
+			gl.startThinking()
+		}
+		if gl.input.movePressed() {
+			gl.thinking.cancel()
+		}
+		select {
+		case r := <-gl.thinking.done:
+			gl.thinking = nil
+			if r.ok && len(r.move.Mods) > 0 {
+				gl.startAnimations(r.move)
+			}
+		default:
+		}
+		return nil
+	}
+
+	// ⑤ 玩家输入：省电状态下也能响应；一旦要播动画再切全速
+	if m, ok := gl.input.handleMouse(gl.logic, gl.lockInput); ok {
+
+		gl.startAnimations(m)
 		return nil
 	}
 
 	return nil
 }
+
+// takeBack 撤销一步；PvE 下连同 AI 的应着一起撤到人类回合
+func (gl *GameLoop) takeBack() {
+	gl.stopThinking()
+	if !gl.logic.TakeBack() {
+		return
+	}
+	for gl.pve && gl.logic.CurrentPlayer != gl.humanSide && gl.logic.TakeBack() {
+	}
+	gl.input.sel = nil
+	gl.rend.syncOutCounts(gl.logic)
+	if gl.rec != nil && len(gl.rec.Moves) > gl.logic.HistoryLen() {
+		gl.rec.Moves = gl.rec.Moves[:gl.logic.HistoryLen()]
+	}
+}
 func (gl *GameLoop) Draw(screen *ebiten.Image) {
-	// 传入 gl 本身，让 drawBoard 能访问 gl.logic、gl.animating、gl.input.selPos
+	// 传入 gl 本身，让 drawBoard 能访问 gl.logic、gl.animating、gl.input.sel
 	gl.rend.drawBoard(screen, gl)
-	gl.header.draw(screen, gl.logic)
+	analysis := ""
+	if p := gl.analysis.Load(); p != nil {
+		analysis = *p
+	}
+	gl.header.draw(screen, gl.logic, gl.input.msg, gl.matchStatus(), analysis)
 }
 func (gl *GameLoop) Layout(_, _ int) (int, int) { return screenW, screenH }
 
@@ -16,19 +16,51 @@ func newHeaderUI() *headerUI { return &headerUI{} }
 
 var colWhite = color.White
 
-func (h *headerUI) draw(screen *ebiten.Image, g *board.Game) {
+func (h *headerUI) draw(screen *ebiten.Image, g *board.Game, msg, status, analysis string) {
 	y := 600 + 50 // header 垂直居中
 	x := 10
 
+	// 第一行之上：AI 的搜索信息（深度、分数、节点、主变例）
+	if analysis != "" {
+		text.Draw(screen, analysis, basicfont.Face7x13, 10, y-25, colWhite)
+	}
+
+	episodes := 1
+	for p := int8(0); p < g.Players(); p++ {
+		if g.Team(p) == p { // 队友同胜，每队只数一次
+			episodes += g.PlayerVictories[p]
+		}
+	}
+	score := fmt.Sprintf("Score | A:%d  B:%d", g.PlayerVictories[0], g.PlayerVictories[1])
+	if g.Players() > 2 { // 多人局每人一项：吃子数/胜局数
+		score = "Score |"
+		for p := int8(0); p < g.Players(); p++ {
+			score += fmt.Sprintf(" %c:%d/%d", 'A'+p, g.Captures(p), g.PlayerVictories[p])
+		}
+	}
 	strs := []string{
 		fmt.Sprintf("Player | %d", g.CurrentPlayer),
-		fmt.Sprintf("Episode | %d", g.PlayerVictories[0]+g.PlayerVictories[1]+1),
+		fmt.Sprintf("Episode | %d", episodes),
 		fmt.Sprintf("Turns | %d", g.TurnCount),
-		fmt.Sprintf("State | %s", map[bool]string{false: "ON GOING", true: "OVER"}[g.GameOver]),
-		fmt.Sprintf("Score | A:%d  B:%d", g.PlayerVictories[0], g.PlayerVictories[1]),
+		fmt.Sprintf("State | %s", g.Result),
+		score,
+	}
+	if v := g.Variant(); v != "" {
+		strs = append(strs, fmt.Sprintf("Variant | %s", v))
 	}
 	for _, s := range strs {
 		text.Draw(screen, s, basicfont.Face7x13, x, y, colWhite)
 		x += len(s)*7 + 30
 	}
+	// 第二行：比赛进度 + 非法走子原因
+	line := status
+	if msg != "" {
+		if line != "" {
+			line += "   "
+		}
+		line += msg
+	}
+	if line != "" {
+		text.Draw(screen, line, basicfont.Face7x13, 10, y+25, colWhite)
+	}
 }
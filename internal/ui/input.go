@@ -8,70 +8,112 @@ import (
 )
 
 type inputHandler struct {
-	selPos    int8 // -1 = 没有选中
-	pvp       bool // true = 双人热座；false = 人机
-	humanSide int8 // 仅 PvE 有意义
+	sel       []int8 // 已选中的己方棋串（按点击顺序），空 = 没有选中
+	pvp       bool   // true = 双人热座；false = 人机
+	humanSide int8   // 仅 PvE 有意义
+	msg       string // 上一次非法走子的原因，显示在 header
 }
 
 const humanSide = board.PlayerA // 0 = 白方由人下，1 = 黑方由 AI 下
 
-// handleMouse 处理点击；合法走子时返回 mods，否则返回 nil
-func (h *inputHandler) handleMouse(g *board.Game, locked bool) []board.Modification {
+// handleMouse 处理点击；合法走子时返回走法，否则 ok=false。
+// 依次点选 1-3 颗相连共线的己子，再点目标格：目标格为第一颗选中子
+// 沿某方向的相邻格（直线走法也可点领头子前方那格），即按 (棋串, 方向) 走子。
+func (h *inputHandler) handleMouse(g *board.Game, locked bool) (m board.Move, ok bool) {
 	if locked {
-		return nil
+		return
 	}
-	
+
 	if !h.pvp && g.CurrentPlayer != h.humanSide {
-		return nil
+		return
 	}
 
 	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		return nil
+		return
 	}
 	x, y := ebiten.CursorPosition()
 	pos := pixelToPos(x, y)
 	if pos < 0 {
-		return nil
+		return
 	}
 
-	token := g.TokenAt(pos)
-	isOwn := token == g.CurrentPlayer
+	// 点己子：加入 / 移出棋串
+	if g.TokenAt(pos) == g.CurrentPlayer {
+		h.toggle(g, pos)
+		h.msg = ""
+		return
+	}
+	if len(h.sel) == 0 {
+		return
+	}
 
-	// 第一次选中
-	if h.selPos == -1 {
-		if isOwn {
-			h.selPos = pos
+	// 点目标格：先按第一颗选中子找方向，再看其余棋子
+	var err error
+	for _, p := range h.sel {
+		for dir := int8(0); dir < 6; dir++ {
+			if g.Neighbor(p, dir) != pos {
+				continue
+			}
+			if m, err = g.GroupMove(h.sel, dir); err == nil {
+				h.sel, h.msg = nil, ""
+				return m, true
+			}
+		}
+	}
+	// 单子时兼容旧的两点输入（尾子 + 远处落点）
+	if len(h.sel) == 1 {
+		if m, err = g.MoveFromPair(h.sel[0], pos); err == nil {
+			h.sel, h.msg = nil, ""
+			return m, true
 		}
-		return nil
 	}
+	if err == nil {
+		h.msg = "click a cell next to the selected marbles"
+	} else {
+		h.msg = err.Error()
+	}
+	h.sel = nil // 清空选中
+	return
+}
 
-	// 已有选中，再点己方 -> 切换选中
-	if isOwn {
-		h.selPos = pos
-		return nil
+// toggle 点击己子：已选中则移出，否则尝试接到棋串上，接不上就重新开始选
+func (h *inputHandler) toggle(g *board.Game, pos int8) {
+	for i, p := range h.sel {
+		if p == pos {
+			rest := append(append([]int8(nil), h.sel[:i]...), h.sel[i+1:]...)
+			if len(rest) > 0 && !g.IsGroup(rest) {
+				rest = nil
+			}
+			h.sel = rest
+			return
+		}
 	}
+	if cand := append(append([]int8(nil), h.sel...), pos); g.IsGroup(cand) {
+		h.sel = cand
+		return
+	}
+	h.sel = []int8{pos}
+}
 
-	// 否则尝试走子
-	ok, _, mods := g.ValidateMove(h.selPos, pos)
-	h.selPos = -1 // 清空选中
-	if ok {
-		return mods
+// undoPressed Backspace / U 悔棋
+func (h *inputHandler) undoPressed(locked bool) bool {
+	if locked {
+		return false
 	}
-	return nil
+	return inpututil.IsKeyJustPressed(ebiten.KeyBackspace) || inpututil.IsKeyJustPressed(ebiten.KeyU)
+}
+
+// nextPressed N 键：比赛中开始下一局
+func (h *inputHandler) nextPressed() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyN)
 }
 
-/* ---------- 像素坐标 -> 格子索引 ---------- */
+// movePressed 空格：让正在思考的 AI 立即按已完成的搜索出手
+func (h *inputHandler) movePressed() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeySpace)
+}
 
-func pixelToPos(x, y int) int8 {
-	best := int8(-1)
-	bestDist := 24 * 24
-	for p := int8(0); p < 61; p++ {
-		dx := x - cellCenters[p][0]
-		dy := y - cellCenters[p][1]
-		d2 := dx*dx + dy*dy
-		if d2 < bestDist {
-			bestDist, best = d2, p
-		}
-	}
-	return best
+// copyPressed C 键：把当前局面文本打到日志，方便复制报告问题
+func (h *inputHandler) copyPressed() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyC)
 }
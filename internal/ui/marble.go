@@ -7,7 +7,7 @@ import (
 // 这里我们已经在 renderer.go 里加载了 marbleAImg, marbleBImg, arrowAImg, arrowBImg, selectedImg
 // marbleUI 只负责当前棋子的“sprite状态”（position, direction, 是否选中）
 // 其实不用额外存一份 sprite，在 renderer.Draw 时只需根据逻辑层的内容重绘即可。
-// 所以这个文件可以略简化，直接让 renderer 取 token、selPos、arrowVisible 去 draw 即可。
+// 所以这个文件可以略简化，直接让 renderer 取 token、sel、arrowVisible 去 draw 即可。
 // 这里只说明思路，不给完整实现。
 
 type marbleUI struct {
@@ -41,9 +41,11 @@ var (
 	boardImg, marbleAImg, marbleBImg  *ebiten.Image
 	arrowAImg, arrowBImg, selectedImg *ebiten.Image
 
-	cellCenters        [61][2]int
+	themeCenters       [board.N][2]int // 主题里标准棋盘的格子中心
+	cellCenters        [][2]int        // 当前棋盘的格子中心，见 layoutCells
+	cellScale          = 1.0           // 大棋盘整体缩小棋子与间距
 	outCoords          [2][6][2]int
-	outCounts          [2]int
+	outCounts          [board.MaxPlayers]int // 只有 A/B 有右侧托盘，C/D 只计数
 	selHalfW, selHalfH int
 )
 
@@ -81,8 +83,8 @@ func parseThemeJSON() {
 
 	for i, v := range def["coordinates"].([]any) {
 		xy := v.([]any)
-		cellCenters[i][0] = int(xy[0].(float64))
-		cellCenters[i][1] = int(xy[1].(float64))
+		themeCenters[i][0] = int(xy[0].(float64))
+		themeCenters[i][1] = int(xy[1].(float64))
 	}
 	outs := def["out_coordinates"].([]any)
 	for p, arr := range outs {
@@ -94,10 +96,55 @@ func parseThemeJSON() {
 	}
 }
 
+// marbleHalf 棋子贴图半径（像素）
+const marbleHalf = 24
+
+// layoutCells 按棋盘边长计算格子中心：标准棋盘直接用主题坐标；
+// 大棋盘沿主题的行 / 列间距外推，并整体缩小到与标准棋盘同样大
+func layoutCells(g *board.Game) {
+	if g.Side() == board.StandardSide {
+		cellCenters, cellScale = themeCenters[:], 1
+		return
+	}
+	// 主题里 0、1 号格同一行相邻，5 号格在下一行首：由此得到列向量 C 与行向量 R
+	t := themeCenters
+	cx, cy := float64(t[1][0]-t[0][0]), float64(t[1][1]-t[0][1])
+	rx, ry := float64(t[5][0]-t[0][0])+cx, float64(t[5][1]-t[0][1])+cy
+	mid := t[board.N/2] // 中心格
+	s := g.Side()
+	cellScale = float64(board.StandardSide-1) / float64(s-1)
+	cellCenters = make([][2]int, g.CellCount())
+	for p := range cellCenters {
+		r, c := g.PosToCoord(int8(p))
+		dr, dc := float64(r-s)*cellScale, float64(c-s)*cellScale
+		cellCenters[p] = [2]int{
+			mid[0] + int(dr*rx+dc*cx),
+			mid[1] + int(dr*ry+dc*cy),
+		}
+	}
+}
+
+// marbleXY 以 c 为中心画棋子时的左上角坐标
+func marbleXY(c [2]int) (float64, float64) {
+	h := marbleHalf * cellScale
+	return float64(c[0]) - h, float64(c[1]) - h
+}
+
 // drawBoard
 func (r *renderer) drawBoard(screen *ebiten.Image, gl *GameLoop) {
-	// 1) 背景棋盘
-	screen.DrawImage(boardImg, nil)
+	// 1) 背景棋盘：大棋盘没有底图，用半透明棋子标出空格
+	if len(cellCenters) == board.N {
+		screen.DrawImage(boardImg, nil)
+	} else {
+		for _, c := range cellCenters {
+			x, y := marbleXY(c)
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(cellScale, cellScale)
+			op.GeoM.Translate(x, y)
+			op.ColorScale.ScaleAlpha(0.15)
+			screen.DrawImage(marbleAImg, op)
+		}
+	}
 
 	// 2) 静止棋子（跳过动画中的起点和终点）
 	moving := make(map[int8]struct{}, len(gl.animating)*2)
@@ -108,7 +155,7 @@ func (r *renderer) drawBoard(screen *ebiten.Image, gl *GameLoop) {
 			moving[anim.to] = struct{}{}
 		}
 	}
-	for pos := int8(0); pos < 61; pos++ {
+	for pos := int8(0); pos < int8(len(cellCenters)); pos++ {
 		if _, busy := moving[pos]; busy {
 			continue
 		}
@@ -116,79 +163,70 @@ func (r *renderer) drawBoard(screen *ebiten.Image, gl *GameLoop) {
 		if token == board.TokenEmpty || token == board.TokenVoid {
 			continue
 		}
-		x := float64(cellCenters[int(pos)][0] - 24)
-		y := float64(cellCenters[int(pos)][1] - 24)
-		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(x, y)
-		if token == board.PlayerA {
-			screen.DrawImage(marbleAImg, op)
-		} else {
-			screen.DrawImage(marbleBImg, op)
-		}
+		x, y := marbleXY(cellCenters[pos])
+		drawMarble(screen, token, x, y)
 	}
 
 	// 3) 选中高亮
-	if sel := gl.input.selPos; sel >= 0 {
-		px := float64(cellCenters[int(sel)][0] - selHalfW)
-		py := float64(cellCenters[int(sel)][1] - selHalfH)
+	for _, sel := range gl.input.sel {
+		px := float64(cellCenters[sel][0]) - float64(selHalfW)*cellScale
+		py := float64(cellCenters[sel][1]) - float64(selHalfH)*cellScale
 		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(cellScale, cellScale)
 		op.GeoM.Translate(px, py)
 		screen.DrawImage(selectedImg, op)
 	}
 
 	// 4) 被推出的棋子 （保持不变）
-	for p := 0; p < 2; p++ {
-		for k := 0; k < outCounts[p]; k++ {
-			ox := float64(outCoords[p][k][0] - 24)
-			oy := float64(outCoords[p][k][1] - 24)
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Translate(ox, oy)
-			if p == int(board.PlayerA) {
-				screen.DrawImage(marbleAImg, op)
-			} else {
-				screen.DrawImage(marbleBImg, op)
-			}
+	for p := range outCoords {
+		for k := 0; k < min(outCounts[p], len(outCoords[p])); k++ {
+			ox, oy := marbleXY(outCoords[p][k])
+			drawMarble(screen, int8(p), ox, oy)
 		}
 	}
 
 	// 5) 动画棋子（覆盖最上层）
 	for _, a := range gl.animating {
 		startXY := func() (float64, float64) {
-			c := cellCenters[a.from]
-			return float64(c[0] - 24), float64(c[1] - 24)
-		}
-		endXY := func() (float64, float64) {
-			if a.to >= 0 { // 普通落子
-				c := cellCenters[int(a.to)]
-				return float64(c[0] - 24), float64(c[1] - 24)
-			}
-			// 推子：用 slotIdx 做索引
-			idx := a.slotIdx // 0‥5
-			oc := outCoords[int(a.piece)][idx]
-			return float64(oc[0] - 24), float64(oc[1] - 24)
+			return marbleXY(cellCenters[a.from])
 		}
+		x, y, _ := a.screenXY(startXY, a.endXY)
+		drawMarble(screen, a.piece, x, y)
+	}
+}
 
-		x, y, _ := a.screenXY(startXY, endXY)
-		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(x, y)
-		if a.piece == board.PlayerA {
-			screen.DrawImage(marbleAImg, op)
-		} else {
-			screen.DrawImage(marbleBImg, op)
-		}
+// drawMarble 在 (x,y) 画一颗 token 方的棋子；C/D 没有单独的贴图，用 A/B 的贴图染色
+func drawMarble(screen *ebiten.Image, token int8, x, y float64) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(cellScale, cellScale)
+	op.GeoM.Translate(x, y)
+	img := marbleAImg
+	if token%2 == 1 {
+		img = marbleBImg
+	}
+	switch token {
+	case board.PlayerC:
+		op.ColorScale.Scale(1, 0.45, 0.45, 1) // 偏红
+	case board.PlayerD:
+		op.ColorScale.Scale(0.45, 0.6, 1, 1) // 偏蓝
 	}
+	screen.DrawImage(img, op)
 }
 
 // applyModifications 需要 g 引用以确定玩家
 func (r *renderer) applyModifications(mods []board.Modification, g *board.Game) {
 	for _, m := range mods {
 		if m.DirIndex == -1 {
-			player := g.TokenAt(m.OldPos)
-			if player == board.PlayerA || player == board.PlayerB {
-				if outCounts[player] < 6 {
-					outCounts[player]++
-				}
+			if player := g.TokenAt(m.OldPos); player >= 0 {
+				outCounts[player]++
 			}
 		}
 	}
 }
+
+// syncOutCounts 悔棋后按逻辑层的被推出数重置右侧计数
+func (r *renderer) syncOutCounts(g *board.Game) {
+	for p := range outCounts {
+		outCounts[p] = int(g.Damages(int8(p)))
+	}
+}
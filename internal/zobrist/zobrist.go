@@ -1,30 +1,42 @@
 // File: internal/zobrist/zobrist.go
 package zobrist
 
-import (
-	"math/rand"
-	"time"
-)
+import "math/rand"
 
 const (
-	Players   = 2  // A / B
-	Positions = 61 // 可落子格子数（固定）
+	MaxPlayers = 4   // 2-4 人局：A / B / C / D
+	Positions  = 127 // 可落子格子数上限（边长 7 的棋盘）
+	MaxDamage  = 20  // 被推出数 / 吃子数上限（含）：一方最多 20 子（边长 7 的两人局）
+
+	seed = 0x5eedab10e // 固定种子：同一局面在每次运行中哈希相同，测试与调试可复现
 )
 
-var Keys [Players][Positions]uint64
+var (
+	Keys        [MaxPlayers][Positions]uint64
+	SideKeys    [MaxPlayers]uint64                // 轮到该玩家走时异或进哈希
+	DamageKeys  [MaxPlayers][MaxDamage + 1]uint64 // 被推出 n 颗
+	CaptureKeys [MaxPlayers][MaxDamage + 1]uint64 // 推出对手 n 颗（3 人局需要单独记录）
+)
 
 func init() {
-	// 用 time.Now 纳秒做种子；如需确定性测试，以固定常量替换即可
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-	for p := 0; p < Players; p++ {
+	rng := rand.New(rand.NewSource(seed))
+	next := func() uint64 {
+		// 避免生成 0（XOR 不起作用）
+		v := rng.Uint64()
+		for v == 0 {
+			v = rng.Uint64()
+		}
+		return v
+	}
+	for p := 0; p < MaxPlayers; p++ {
 		for i := 0; i < Positions; i++ {
-			// 避免生成 0（XOR 不起作用）
-			v := rng.Uint64()
-			for v == 0 {
-				v = rng.Uint64()
-			}
-			Keys[p][i] = v
+			Keys[p][i] = next()
+		}
+		for n := 0; n <= MaxDamage; n++ {
+			DamageKeys[p][n] = next()
+			CaptureKeys[p][n] = next()
 		}
+		SideKeys[p] = next()
 	}
 }
 
@@ -36,12 +48,38 @@ func Toggle(hash uint64, player, pos int8) uint64 {
 	return hash ^ Keys[player][pos]
 }
 
-// HashFromCells 根据一维 cells 切片计算整盘哈希。
-// cells[pos] == 0 / 1 表示棋子；其它值（空 / VOID）会被忽略。
+// ToggleSide 加入 / 移走“轮到 player 走”；换手时对新旧两方各调用一次
+func ToggleSide(hash uint64, player int8) uint64 { return hash ^ SideKeys[player] }
+
+// ToggleDamage 对 player 被推出 n 颗的键做一次 XOR；n 变化时先移走旧值再加入新值
+func ToggleDamage(hash uint64, player, n int8) uint64 {
+	return hash ^ DamageKeys[player][n]
+}
+
+// ToggleCapture 对 player 已推出对手 n 颗的键做一次 XOR，用法同 ToggleDamage
+func ToggleCapture(hash uint64, player, n int8) uint64 {
+	return hash ^ CaptureKeys[player][n]
+}
+
+// Hash 计算完整局面哈希：棋子 + 行棋方 + 各方被推出数与吃子数；
+// damages / captures 按玩家下标排列，长度即人数。
+func Hash(cells []int8, side int8, damages, captures []int8) uint64 {
+	h := ToggleSide(HashFromCells(cells), side)
+	for p, n := range damages {
+		h = ToggleDamage(h, int8(p), n)
+	}
+	for p, n := range captures {
+		h = ToggleCapture(h, int8(p), n)
+	}
+	return h
+}
+
+// HashFromCells 根据一维 cells 切片计算整盘哈希（只含棋子）。
+// cells[pos] 为 0..MaxPlayers-1 表示棋子；其它值（空 / VOID）会被忽略。
 func HashFromCells(cells []int8) uint64 {
 	var h uint64
 	for pos, token := range cells {
-		if token == 0 || token == 1 { // 只对玩家 A/B 做 XOR
+		if token >= 0 && token < MaxPlayers {
 			h ^= Keys[token][pos]
 		}
 	}